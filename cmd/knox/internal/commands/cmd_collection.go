@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/urfave/cli/v3"
+)
+
+func NewCollectionCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "collection",
+		Usage: "manage collections in linked vaults",
+		Commands: []*cli.Command{
+			newCollectionNewCommand(),
+			newCollectionListCommand(),
+			newCollectionRenameCommand(),
+			newCollectionDescribeCommand(),
+			newCollectionRemoveCommand(),
+		},
+	}
+}
+
+func newCollectionNewCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "new",
+		Usage:     "create a collection",
+		ArgsUsage: "<collection@vault>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "description",
+				Usage: "collection description",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(1, "collection@vault is required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.CollectionNewHandler(cmd.Args().First(), cmd.String("description"))
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func newCollectionListCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "ls",
+		Usage:     "list the collections in a vault",
+		ArgsUsage: "<vault-alias>",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(1, "vault alias is required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.CollectionListHandler(cmd.Args().First())
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func newCollectionRenameCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rename",
+		Usage:     "rename a collection",
+		ArgsUsage: "<collection@vault> <new-name>",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(2, "collection@vault and new name are required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.CollectionRenameHandler(cmd.Args().Get(0), cmd.Args().Get(1))
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func newCollectionDescribeCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "describe",
+		Usage:     "set the description of a collection",
+		ArgsUsage: "<collection@vault> <description>",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(2, "collection@vault and description are required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.CollectionDescribeHandler(cmd.Args().Get(0), cmd.Args().Get(1))
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func newCollectionRemoveCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rm",
+		Usage:     "delete a collection",
+		ArgsUsage: "<collection@vault>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "also delete the secrets in a non-empty collection",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(1, "collection@vault is required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.CollectionRemoveHandler(cmd.Args().First(), cmd.Bool("force"))
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/urfave/cli/v3"
+)
+
+func NewConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "config",
+		Usage:     "get or set workspace settings",
+		ArgsUsage: "[key] [value]",
+		Description: fmt.Sprintf("With no arguments or --list, shows every setting. With a key, prints its value;\n"+
+			"with a key and a value, sets it.\n\nSettings: %s", strings.Join(workspace.SettingKeys(), ", ")),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "list",
+				Usage: "list every setting with its effective value",
+			},
+			&cli.BoolFlag{
+				Name:  "unset",
+				Usage: "remove a setting so its default applies",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			var (
+				n   ast.Node
+				err error
+			)
+
+			args := cmd.Args()
+			switch {
+			case cmd.Bool("unset"):
+				if err := common.ExpectExactArgCount(1, "--unset takes exactly one key", args); err != nil {
+					return err
+				}
+				n, err = handlers.ConfigUnsetHandler(args.First())
+			case cmd.Bool("list") || args.Len() == 0:
+				if args.Len() != 0 {
+					return errs.New(error_codes.ValidationErrCode, "--list takes no arguments").WithContext("got", args.Len())
+				}
+				n, err = handlers.ConfigListHandler()
+			case args.Len() == 1:
+				n, err = handlers.ConfigGetHandler(args.First())
+			default:
+				if err := common.ExpectExactArgCount(2, "expected a key and a value", args); err != nil {
+					return err
+				}
+				n, err = handlers.ConfigSetHandler(args.Get(0), args.Get(1))
+			}
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
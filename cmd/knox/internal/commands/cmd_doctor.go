@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/urfave/cli/v3"
+)
+
+func NewDoctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "doctor",
+		Usage: "check the workspace, its projects and linked vaults for problems",
+		Description: "doctor only reports problems unless --fix is given. Fixes are limited to safe\n" +
+			"changes: tightening permissions, applying migrations, recreating the projects\n" +
+			"directory and finding moved vaults by id. Exits with status 1 while errors remain.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "fix",
+				Usage: "apply the fixes doctor can make itself",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(0, "doctor takes no arguments", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, healthy, err := handlers.DoctorHandler(cmd.Bool("fix"))
+			if err != nil {
+				return err
+			}
+
+			if err := common.Render(cmd, n); err != nil {
+				return err
+			}
+			if !healthy {
+				return cli.Exit("", 1)
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/tomdoesdev/knox/internal/envexport"
+	"github.com/urfave/cli/v3"
+)
+
+func NewExportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "print the project's resolved secrets in a format other tools can read",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:      "format",
+				Aliases:   []string{"f"},
+				Usage:     fmt.Sprintf("export format (%s)", strings.Join(envexport.Formats(), ", ")),
+				Value:     envexport.DotenvFormat,
+				Validator: envexport.ValidateFormat,
+			},
+			&cli.StringFlag{
+				Name:  "out",
+				Usage: "write to this file, created with 0600 permissions, instead of stdout",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "replace the --out file if it already exists",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(0, "unexpected arguments, use --project to choose the project", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.ExportHandler(common.ProjectName(cmd), cmd.String("format"), cmd.String("out"), cmd.Bool("force"), os.Stdout)
+			if err != nil || n == nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
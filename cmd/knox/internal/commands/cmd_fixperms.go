@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/urfave/cli/v3"
+)
+
+func NewFixPermsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "fix-perms",
+		Usage: "remove group and world access from the workspace and its linked vaults",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "only list the paths that would be changed",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(0, "fix-perms takes no arguments", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.FixPermsHandler(cmd.Bool("dry-run"))
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	"os"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/urfave/cli/v3"
+)
+
+func NewImportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "import",
+		Usage: "import secrets from other formats",
+		Commands: []*cli.Command{
+			newImportDotenvCommand(),
+		},
+	}
+}
+
+func newImportDotenvCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "dotenv",
+		Usage:     "import the assignments of a .env file into a collection",
+		ArgsUsage: "<file> --into <collection@vault>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "into",
+				Usage:    "collection@vault to import into",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  handlers.ConflictSkip,
+				Usage: "keep existing secrets that have a different value",
+			},
+			&cli.BoolFlag{
+				Name:  handlers.ConflictOverwrite,
+				Usage: "replace existing secrets that have a different value",
+			},
+			&cli.BoolFlag{
+				Name:  handlers.ConflictFail,
+				Usage: "import nothing if any existing secret has a different value (default)",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "only preview the import",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "import without asking for confirmation",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(1, "dotenv file is required", cmd.Args()); err != nil {
+				return err
+			}
+
+			conflict, err := conflictPolicy(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts := handlers.ImportDotenvOptions{
+				Path:     cmd.Args().First(),
+				Target:   cmd.String("into"),
+				Conflict: conflict,
+				DryRun:   cmd.Bool("dry-run"),
+				Yes:      cmd.Bool("yes"),
+			}
+			if common.IsTerminal(os.Stdin) {
+				opts.Confirm = confirmImport
+			}
+
+			n, err := handlers.ImportDotenvHandler(opts)
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+// conflictPolicy returns the conflict policy chosen by --skip, --overwrite or
+// --fail, at most one of which may be given
+func conflictPolicy(cmd *cli.Command) (string, error) {
+	policy := ""
+	for _, name := range []string{handlers.ConflictSkip, handlers.ConflictOverwrite, handlers.ConflictFail} {
+		if !cmd.Bool(name) {
+			continue
+		}
+		if policy != "" {
+			return "", errs.New(error_codes.ValidationErrCode, "only one of --skip, --overwrite and --fail can be given")
+		}
+		policy = name
+	}
+
+	if policy == "" {
+		return handlers.ConflictFail, nil
+	}
+	return policy, nil
+}
+
+// confirmImport shows the preview on stderr, so it stays out of structured
+// output, and asks whether to go ahead
+func confirmImport(preview ast.Node) (bool, error) {
+	r, err := renderers.Lookup(renderers.DefaultFormat)
+	if err != nil {
+		return false, err
+	}
+	if err := r.Render(os.Stderr, preview); err != nil {
+		return false, err
+	}
+
+	return common.Confirm("Import these secrets?")
+}
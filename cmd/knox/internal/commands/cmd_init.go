@@ -2,41 +2,12 @@ package commands
 
 import (
 	"context"
-	"fmt"
 
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
 	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
-	"github.com/tomdoesdev/knox/kit/ast"
 	"github.com/urfave/cli/v3"
 )
 
-func initVisitor(n ast.Node) error {
-	if n.Type() == "result" {
-		//Ignore the root as it has no content
-		return nil
-	}
-	var path string
-	var created bool
-
-	if attr, ok := n.GetAttribute("created"); ok {
-		v, err := attr.AsBool()
-		if err != nil {
-			return err
-		}
-		created = v
-	}
-
-	if attr, ok := n.GetAttribute("path"); ok {
-		v, err := attr.AsString()
-		if err != nil {
-			return err
-		}
-		path = v
-	}
-
-	fmt.Printf("%s - created=%t path=%s\n", n.Content().String(), created, path)
-	return nil
-}
-
 func NewInitCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "init",
@@ -47,7 +18,7 @@ func NewInitCommand() *cli.Command {
 				return err
 			}
 
-			return ast.WithPreOrderTraversal(n, initVisitor)
+			return common.Render(cmd, n)
 		},
 	}
 }
@@ -19,6 +19,10 @@ func NewLinkCommand() *cli.Command {
 				Usage:    "alias for the vault in the workspace",
 				Required: true,
 			},
+			&cli.BoolFlag{
+				Name:  "relative",
+				Usage: "store the path relative to the workspace, $KNOX_ROOT or ~ so the workspace can be shared",
+			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			if err := common.ExpectExactArgCount(1, "vault path is required", cmd.Args()); err != nil {
@@ -27,7 +31,12 @@ func NewLinkCommand() *cli.Command {
 			vaultPath := cmd.Args().First()
 			alias := cmd.String("alias")
 
-			return handlers.LinkVaultHandler(vaultPath, alias)
+			n, err := handlers.LinkVaultHandler(vaultPath, alias, cmd.Bool("relative"))
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
 		},
 	}
 }
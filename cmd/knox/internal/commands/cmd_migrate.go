@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/urfave/cli/v3"
+)
+
+func NewMigrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "upgrade the workspace and linked vault schemas",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "show pending migrations without applying them",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			n, err := handlers.MigrateHandler(cmd.Bool("dry-run"))
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
 	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
@@ -15,7 +16,7 @@ func NewNewCommand() *cli.Command {
 		Commands: []*cli.Command{
 			newNewProjectCommand(),
 			newVaultCommand(),
-			// TODO: newNewSecretCommand(), etc.
+			newNewSecretCommand(),
 		},
 	}
 }
@@ -40,7 +41,12 @@ func newNewProjectCommand() *cli.Command {
 			name := cmd.Args().First()
 			description := cmd.String("description")
 
-			return handlers.NewProjectHandler(name, description)
+			n, err := handlers.NewProjectHandler(name, description)
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
 		},
 	}
 }
@@ -64,7 +70,41 @@ func newVaultCommand() *cli.Command {
 			alias := cmd.Args().First()
 			path := cmd.String("path")
 
-			return handlers.NewVaultHandler(alias, path)
+			passphrase, err := common.NewPassphrase(fmt.Sprintf("Passphrase for new vault '%s': ", alias))
+			if err != nil {
+				return err
+			}
+
+			n, err := handlers.NewVaultHandler(alias, path, passphrase)
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func newNewSecretCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "secret",
+		Usage:     "create a new secret, failing if the key already exists",
+		ArgsUsage: "<key> [value|-]",
+		Flags: []cli.Flag{
+			newTargetFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			key, value, err := readKeyValueArgs(cmd)
+			if err != nil {
+				return err
+			}
+
+			n, err := handlers.SetSecretHandler(common.ProjectName(cmd), cmd.String("target"), key, value, false)
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
 		},
 	}
 }
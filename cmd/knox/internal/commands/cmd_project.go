@@ -18,6 +18,7 @@ func NewProjectCommand() *cli.Command {
 			newProjectListSecretsCommand(),
 			newProjectAddSecretCommand(),
 			newProjectRemoveSecretCommand(),
+			newProjectValidateCommand(),
 		},
 	}
 }
@@ -27,7 +28,12 @@ func newProjectListCommand() *cli.Command {
 		Name:  "list",
 		Usage: "list all projects",
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			return handlers.ProjectListHandler()
+			n, err := handlers.ProjectListHandler()
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
 		},
 	}
 }
@@ -43,7 +49,12 @@ func newProjectDeleteCommand() *cli.Command {
 			}
 
 			name := cmd.Args().First()
-			return handlers.ProjectDeleteHandler(name)
+			n, err := handlers.ProjectDeleteHandler(name)
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
 		},
 	}
 }
@@ -54,11 +65,17 @@ func newProjectListSecretsCommand() *cli.Command {
 		Usage:     "list secrets in a project",
 		ArgsUsage: "[project-name]",
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			var name string
-			if cmd.Args().Len() == 1 {
-				name = cmd.Args().First()
+			name, _, err := projectArgs(cmd, 0, "at most one project name can be given")
+			if err != nil {
+				return err
+			}
+
+			n, err := handlers.ProjectListSecretsHandler(name)
+			if err != nil {
+				return err
 			}
-			return handlers.ProjectListSecretsHandler(name)
+
+			return common.Render(cmd, n)
 		},
 	}
 }
@@ -67,18 +84,22 @@ func newProjectAddSecretCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "add-secret",
 		Usage:     "add a secret to a project",
-		ArgsUsage: "<project-name> <logical-name> <vault-path@vault-alias>",
+		ArgsUsage: "[project-name] <logical-name> <secret@vault/collection>",
+		Flags: []cli.Flag{
+			newStrictFlag(),
+		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			if err := common.ExpectExactArgCount(3,
-				"project name, logical name, and secret reference are required", cmd.Args()); err != nil {
+			projectName, args, err := projectArgs(cmd, 2, "logical name and secret reference are required")
+			if err != nil {
 				return err
 			}
 
-			projectName := cmd.Args().Get(0)
-			logicalName := cmd.Args().Get(1)
-			secretRef := cmd.Args().Get(2)
+			n, err := handlers.ProjectAddSecretHandler(projectName, args[0], args[1], cmd.Bool("strict"))
+			if err != nil {
+				return err
+			}
 
-			return handlers.ProjectAddSecretHandler(projectName, logicalName, secretRef)
+			return common.Render(cmd, n)
 		},
 	}
 }
@@ -87,18 +108,67 @@ func newProjectRemoveSecretCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "remove-secret",
 		Usage:     "remove a secret from a project",
-		ArgsUsage: "<project-name> <logical-name>",
+		ArgsUsage: "[project-name] <logical-name>",
 		Action: func(ctx context.Context, cmd *cli.Command) error {
+			projectName, args, err := projectArgs(cmd, 1, "logical name is required")
+			if err != nil {
+				return err
+			}
+
+			n, err := handlers.ProjectRemoveSecretHandler(projectName, args[0])
+			if err != nil {
+				return err
+			}
 
-			if err := common.ExpectExactArgCount(2,
-				"project name and logical name are required", cmd.Args()); err != nil {
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func newProjectValidateCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "validate",
+		Usage:     "check that a project's secret references exist in their vaults",
+		ArgsUsage: "[project-name]",
+		Flags: []cli.Flag{
+			newStrictFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			name, _, err := projectArgs(cmd, 0, "at most one project name can be given")
+			if err != nil {
 				return err
 			}
 
-			projectName := cmd.Args().Get(0)
-			logicalName := cmd.Args().Get(1)
+			n, err := handlers.ProjectValidateHandler(name, cmd.Bool("strict"))
+			if err != nil {
+				return err
+			}
 
-			return handlers.ProjectRemoveSecretHandler(projectName, logicalName)
+			return common.Render(cmd, n)
 		},
 	}
 }
+
+func newStrictFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "strict",
+		Usage: "treat missing collections and secrets as errors instead of warnings",
+	}
+}
+
+// projectArgs splits the arguments of a command taking an optional leading
+// project name followed by count further arguments. Without a leading project
+// name, the project comes from --project or KNOX_PROJECT, and is empty when the
+// current project should be used.
+func projectArgs(cmd *cli.Command, count int, message string) (string, []string, error) {
+	args := cmd.Args()
+	if err := common.ExpectArgCountBetween(count, count+1, message, args); err != nil {
+		return "", nil, err
+	}
+
+	if args.Len() == count+1 {
+		return args.First(), args.Tail(), nil
+	}
+
+	return common.ProjectName(cmd), args.Slice(), nil
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/urfave/cli/v3"
+)
+
+func NewRelinkCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "relink",
+		Usage:     "point a linked vault alias at the vault's new path",
+		ArgsUsage: "<vault-alias> <new-path>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "relink even if the vault at the new path is a different vault",
+			},
+			&cli.BoolFlag{
+				Name:  "relative",
+				Usage: "store the path relative to the workspace, $KNOX_ROOT or ~ so the workspace can be shared",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(2, "vault alias and new path are required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.RelinkHandler(cmd.Args().Get(0), cmd.Args().Get(1), cmd.Bool("force"), cmd.Bool("relative"))
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
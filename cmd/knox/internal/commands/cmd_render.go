@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"context"
+	"os"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/urfave/cli/v3"
+)
+
+func NewRenderCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "render",
+		Usage:     "render an .env.template with project secrets to stdout",
+		ArgsUsage: "[template]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "out",
+				Usage: "write to this file, created with 0600 permissions, instead of stdout",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if cmd.Args().Len() > 1 {
+				return errs.New(error_codes.ValidationErrCode, "at most one template path can be given").
+					WithContext("got", cmd.Args().Len())
+			}
+
+			templatePath := handlers.DefaultTemplatePath
+			if cmd.Args().Len() == 1 {
+				templatePath = cmd.Args().First()
+			}
+
+			n, err := handlers.RenderHandler(common.ProjectName(cmd), templatePath, cmd.String("out"), os.Stdout)
+			if err != nil || n == nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/urfave/cli/v3"
+)
+
+func NewRunCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "run",
+		Usage:     "run a command with project secrets in its environment",
+		ArgsUsage: "[--project name] [--template file] -- <command> [args...]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "template",
+				Usage: "render this .env.template and pass its variables instead of the project's secret map",
+			},
+			&cli.StringSliceFlag{
+				Name:  "allow",
+				Usage: "additional environment variable to pass through, NAME or PREFIX*",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			argv := cmd.Args().Slice()
+			if len(argv) == 0 {
+				return errs.New(error_codes.ValidationErrCode, "command is required, use knox run -- <command> [args...]")
+			}
+
+			code, err := handlers.RunHandler(common.ProjectName(cmd), cmd.String("template"), cmd.StringSlice("allow"), argv)
+			if err != nil {
+				return err
+			}
+			if code != 0 {
+				return cli.Exit("", code)
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/urfave/cli/v3"
+)
+
+func newTargetFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "target",
+		Aliases: []string{"t"},
+		Usage:   "collection@vault to operate on (defaults to the current project's secret map)",
+	}
+}
+
+func NewSetCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "set",
+		Usage:     "set a secret value",
+		ArgsUsage: "<key> [value|-]",
+		Flags: []cli.Flag{
+			newTargetFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			key, value, err := readKeyValueArgs(cmd)
+			if err != nil {
+				return err
+			}
+
+			n, err := handlers.SetSecretHandler(common.ProjectName(cmd), cmd.String("target"), key, value, true)
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func NewGetCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "get",
+		Usage:     "print a secret value",
+		ArgsUsage: "<key>",
+		Flags: []cli.Flag{
+			newTargetFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(1, "secret key is required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.GetSecretHandler(common.ProjectName(cmd), cmd.String("target"), cmd.Args().First())
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func NewRmCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rm",
+		Usage:     "remove a secret value",
+		ArgsUsage: "<key>",
+		Flags: []cli.Flag{
+			newTargetFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(1, "secret key is required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.RemoveSecretHandler(common.ProjectName(cmd), cmd.String("target"), cmd.Args().First())
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func NewHistoryCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "history",
+		Usage:     "list the versions of a secret",
+		ArgsUsage: "<key>",
+		Flags: []cli.Flag{
+			newTargetFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(1, "secret key is required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.HistoryHandler(common.ProjectName(cmd), cmd.String("target"), cmd.Args().First())
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func NewRollbackCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rollback",
+		Usage:     "restore a previous version of a secret",
+		ArgsUsage: "<key> --to <version>",
+		Flags: []cli.Flag{
+			newTargetFlag(),
+			&cli.IntFlag{
+				Name:     "to",
+				Usage:    "version to restore, see knox history",
+				Required: true,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(1, "secret key is required", cmd.Args()); err != nil {
+				return err
+			}
+
+			version := cmd.Int("to")
+			if version < 1 {
+				return errs.New(error_codes.ValidationErrCode, "version must be a positive number").WithContext("to", version)
+			}
+
+			n, err := handlers.RollbackHandler(common.ProjectName(cmd), cmd.String("target"), cmd.Args().First(), version)
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func NewLsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ls",
+		Usage: "list workspace resources",
+		Commands: []*cli.Command{
+			newLsSecretsCommand(),
+		},
+	}
+}
+
+func newLsSecretsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "secrets",
+		Usage: "list secrets in a collection or the current project",
+		Flags: []cli.Flag{
+			newTargetFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(0, "unexpected arguments, use --target collection@vault", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.ListSecretsHandler(common.ProjectName(cmd), cmd.String("target"))
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+// readKeyValueArgs reads "<key> [value|-]" arguments. The value is read from
+// stdin when given as "-" or when stdin is not a terminal, and is otherwise
+// prompted for without echo so it never has to appear in shell history.
+func readKeyValueArgs(cmd *cli.Command) (string, string, error) {
+	args := cmd.Args()
+	if args.Len() < 1 || args.Len() > 2 {
+		return "", "", errs.New(error_codes.ValidationErrCode, "secret key and optional value are required").
+			WithContext("got", args.Len()).
+			WithContext("expected", "1 or 2")
+	}
+
+	key := args.Get(0)
+
+	switch {
+	case args.Len() == 2 && args.Get(1) != "-":
+		return key, args.Get(1), nil
+	case args.Len() == 2 || !common.IsTerminal(os.Stdin):
+		value, err := common.ReadStdin()
+		return key, value, err
+	default:
+		value, err := common.ReadHidden(fmt.Sprintf("Value for '%s': ", key))
+		return key, value, err
+	}
+}
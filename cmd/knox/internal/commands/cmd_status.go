@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
 	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
 	"github.com/urfave/cli/v3"
 )
@@ -12,7 +13,12 @@ func NewStatusCommand() *cli.Command {
 		Name:  "status",
 		Usage: "show workspace status information",
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			return handlers.StatusHandler()
+			n, err := handlers.StatusHandler()
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
 		},
 	}
 }
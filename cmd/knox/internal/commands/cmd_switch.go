@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/urfave/cli/v3"
+)
+
+func NewSwitchCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "switch",
+		Usage:     "set the workspace's current project",
+		ArgsUsage: "<project-name>",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(1, "project name is required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.SwitchHandler(cmd.Args().First())
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/urfave/cli/v3"
+)
+
+func NewTidyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "tidy",
+		Usage: "prune or repair dead vault links and references to unlinked vaults",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "only report problems",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "prune every problem without prompting",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			n, err := handlers.TidyHandler(handlers.TidyOptions{
+				DryRun: cmd.Bool("dry-run"),
+				Yes:    cmd.Bool("yes"),
+			})
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
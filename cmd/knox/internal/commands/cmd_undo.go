@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/urfave/cli/v3"
+)
+
+func NewUndoCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "undo",
+		Usage: "restore the vaults and projects saved before the last destructive command",
+		Description: "Destructive commands such as rm, collection rm, project delete and vault restore\n" +
+			"snapshot what they change first. undo puts the most recent snapshot back.\n" +
+			"It refuses to when those files were changed after the snapshot, since the\n" +
+			"changes would be lost, unless --force is given.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "list",
+				Usage: "list the snapshots instead of restoring one",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "undo even if the files were changed after the snapshot was taken",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(0, "undo takes no arguments", cmd.Args()); err != nil {
+				return err
+			}
+
+			var n ast.Node
+			var err error
+			if cmd.Bool("list") {
+				n, err = handlers.UndoListHandler()
+			} else {
+				n, err = handlers.UndoHandler(cmd.Bool("force"))
+			}
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
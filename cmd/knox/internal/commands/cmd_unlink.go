@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/urfave/cli/v3"
+)
+
+func NewUnlinkCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "unlink",
+		Usage:     "remove a vault link from the current workspace",
+		ArgsUsage: "<vault-alias>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "unlink even if project secrets still reference the vault",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(1, "vault alias is required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.UnlinkHandler(cmd.Args().First(), cmd.Bool("force"))
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
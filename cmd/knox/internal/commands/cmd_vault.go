@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/handlers"
+	"github.com/urfave/cli/v3"
+)
+
+func NewVaultCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "vault",
+		Usage: "manage linked vaults",
+		Commands: []*cli.Command{
+			newVaultEncryptCommand(),
+			newVaultBackupCommand(),
+			newVaultRestoreCommand(),
+		},
+	}
+}
+
+func newVaultEncryptCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "encrypt",
+		Usage:     "encrypt the secret values of a plaintext vault",
+		ArgsUsage: "<vault-alias>",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(1, "vault alias is required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.VaultEncryptHandler(cmd.Args().First())
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func newVaultBackupCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "backup",
+		Usage:     "write a consistent snapshot of a vault, safe while the vault is in use",
+		ArgsUsage: "<vault-alias> [dest]",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectArgCountBetween(1, 2, "vault alias and optional destination are required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.VaultBackupHandler(cmd.Args().Get(0), cmd.Args().Get(1))
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
+
+func newVaultRestoreCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "restore",
+		Usage: "replace a vault with a snapshot made by knox vault backup",
+		Description: "The current vault is saved as an undo snapshot before it is replaced,\n" +
+			"so 'knox undo' puts it back. When snapshot_retention is 0 it is backed up\n" +
+			"next to the vault instead.",
+		ArgsUsage: "<vault-alias> <snapshot>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "restore even if the snapshot is of a different vault",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := common.ExpectExactArgCount(2, "vault alias and snapshot path are required", cmd.Args()); err != nil {
+				return err
+			}
+
+			n, err := handlers.VaultRestoreHandler(cmd.Args().Get(0), cmd.Args().Get(1), cmd.Bool("force"))
+			if err != nil {
+				return err
+			}
+
+			return common.Render(cmd, n)
+		},
+	}
+}
@@ -1 +1,71 @@
 package common
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/urfave/cli/v3"
+)
+
+// OutputFlagName is the global flag selecting the output format
+const OutputFlagName = "output"
+
+// NewOutputFlag returns the global --output/-o flag
+func NewOutputFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    OutputFlagName,
+		Aliases: []string{"o"},
+		Usage:   fmt.Sprintf("output format (%s)", strings.Join(renderers.Formats(), ", ")),
+		Value:   renderers.DefaultFormat,
+		Validator: func(format string) error {
+			_, err := renderers.Lookup(format)
+			return err
+		},
+	}
+}
+
+// Render writes a handler result to stdout in the format selected by --output
+func Render(cmd *cli.Command, n ast.Node) error {
+	r, err := renderers.Lookup(OutputFormat(cmd))
+	if err != nil {
+		return err
+	}
+
+	return r.Render(os.Stdout, n)
+}
+
+// OutputFormat returns the format given with --output, falling back to the
+// workspace's default_output_format setting when the flag is not set
+func OutputFormat(cmd *cli.Command) string {
+	if cmd.IsSet(OutputFlagName) {
+		return cmd.String(OutputFlagName)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return renderers.DefaultFormat
+	}
+
+	// The setting is read without opening the workspace, so commands such as
+	// migrate --dry-run and doctor leave its database untouched
+	dir, err := workspace.FindWorkspaceDir(cwd)
+	if err != nil {
+		return renderers.DefaultFormat
+	}
+
+	format, err := workspace.ReadConfig(dir, workspace.DefaultOutputFormatSetting)
+	if err != nil {
+		return renderers.DefaultFormat
+	}
+
+	return format
+}
+
+// Warn prints a warning to stderr so it never mixes with command output
+func Warn(format string, args ...any) {
+	_, _ = fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+}
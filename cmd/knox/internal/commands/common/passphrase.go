@@ -0,0 +1,70 @@
+package common
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// PassphraseEnvVar holds a vault passphrase for non-interactive use
+const PassphraseEnvVar = "KNOX_PASSPHRASE"
+
+// Passphrase returns the vault passphrase from KNOX_PASSPHRASE or prompts for it
+func Passphrase(prompt string) (string, error) {
+	if passphrase, ok := os.LookupEnv(PassphraseEnvVar); ok {
+		return passphrase, nil
+	}
+
+	return ReadHidden(prompt)
+}
+
+// NewPassphrase returns a passphrase for a newly encrypted vault from KNOX_PASSPHRASE,
+// or prompts for it twice and checks both entries match
+func NewPassphrase(prompt string) (string, error) {
+	if passphrase, ok := os.LookupEnv(PassphraseEnvVar); ok {
+		if passphrase == "" {
+			return "", errs.New(error_codes.VaultPassphraseErrCode, "vault passphrase cannot be empty").WithContext("env", PassphraseEnvVar)
+		}
+		return passphrase, nil
+	}
+
+	passphrase, err := ReadHidden(prompt)
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errs.New(error_codes.VaultPassphraseErrCode, "vault passphrase cannot be empty")
+	}
+
+	confirmation, err := ReadHidden("Confirm passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirmation != passphrase {
+		return "", errs.New(error_codes.VaultPassphraseErrCode, "passphrases do not match")
+	}
+
+	return passphrase, nil
+}
+
+// UnlockVault unlocks an encrypted vault, asking for its passphrase when needed.
+// Plaintext vaults are left as they are with a warning.
+func UnlockVault(alias string, v *vault.Vault) error {
+	if !v.IsEncrypted() {
+		Warn("vault '%s' is not encrypted, run 'knox vault encrypt %s'", alias, alias)
+		return nil
+	}
+	if !v.IsLocked() {
+		return nil
+	}
+
+	passphrase, err := Passphrase(fmt.Sprintf("Passphrase for vault '%s': ", alias))
+	if err != nil {
+		return err
+	}
+
+	return v.Unlock(passphrase)
+}
@@ -0,0 +1,26 @@
+package common
+
+import "github.com/urfave/cli/v3"
+
+const (
+	// ProjectFlagName is the global flag selecting the project to operate on
+	ProjectFlagName = "project"
+	// ProjectEnvVar overrides the current project when --project is not given
+	ProjectEnvVar = "KNOX_PROJECT"
+)
+
+// NewProjectFlag returns the global --project/-p flag
+func NewProjectFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    ProjectFlagName,
+		Aliases: []string{"p"},
+		Usage:   "project to use instead of the workspace's current project",
+		Sources: cli.EnvVars(ProjectEnvVar),
+	}
+}
+
+// ProjectName returns the project selected with --project or KNOX_PROJECT, or
+// an empty string when the current project should be used
+func ProjectName(cmd *cli.Command) string {
+	return cmd.String(ProjectFlagName)
+}
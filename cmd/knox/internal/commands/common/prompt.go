@@ -0,0 +1,79 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"golang.org/x/term"
+)
+
+// IsTerminal reports whether f is connected to a terminal
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// Prompt prints prompt to stderr and reads a line from the terminal
+func Prompt(prompt string) (string, error) {
+	if !IsTerminal(os.Stdin) {
+		return "", errs.New(error_codes.ValidationErrCode, "cannot prompt for input, stdin is not a terminal")
+	}
+
+	_, _ = fmt.Fprint(os.Stderr, prompt)
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", errs.Wrap(err, error_codes.ValidationErrCode, "failed to read input")
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+// Confirm asks a yes or no question on the terminal, defaulting to no
+func Confirm(prompt string) (bool, error) {
+	answer, err := Prompt(prompt + " [y/N] ")
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// ReadHidden prints prompt to stderr and reads a line from the terminal without echoing it
+func ReadHidden(prompt string) (string, error) {
+	if !IsTerminal(os.Stdin) {
+		return "", errs.New(error_codes.ValidationErrCode, "cannot prompt for input, stdin is not a terminal")
+	}
+
+	_, _ = fmt.Fprint(os.Stderr, prompt)
+	value, err := term.ReadPassword(int(os.Stdin.Fd()))
+	_, _ = fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", errs.Wrap(err, error_codes.ValidationErrCode, "failed to read input")
+	}
+
+	return string(value), nil
+}
+
+// ReadStdin reads all of stdin, dropping a single trailing newline
+func ReadStdin() (string, error) {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", errs.Wrap(err, error_codes.ValidationErrCode, "failed to read stdin")
+	}
+
+	value := strings.TrimSuffix(string(data), "\n")
+	value = strings.TrimSuffix(value, "\r")
+
+	return value, nil
+}
@@ -18,3 +18,16 @@ func ExpectExactArgCount(count int, message string, args cli.Args) error {
 
 	return nil
 }
+
+func ExpectArgCountBetween(min, max int, message string, args cli.Args) error {
+	argsLen := args.Len()
+
+	if argsLen < min || argsLen > max {
+		return errs.New(error_codes.ValidationErrCode, message).
+			WithContext("got", argsLen).
+			WithContext("min", min).
+			WithContext("max", max)
+	}
+
+	return nil
+}
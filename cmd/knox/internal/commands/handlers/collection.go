@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+)
+
+func CollectionNewHandler(target, description string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		ref, err := workspace.ParseCollectionReference(target)
+		if err != nil {
+			return err
+		}
+
+		err = withVault(ws, ref.Vault, func(v *vault.Vault) error {
+			return v.CreateCollection(ref.Collection, description)
+		})
+		if err != nil {
+			return err
+		}
+
+		addCollectionReference(b, ref).
+			Attr("description", description).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Created collection '%s'", ref)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+func CollectionListHandler(alias string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		return withVault(ws, alias, func(v *vault.Vault) error {
+			collections, err := v.ListCollections()
+			if err != nil {
+				return err
+			}
+
+			b.Attr("vault", alias)
+
+			b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "collections")
+			if len(collections) > 0 {
+				b.Content(fmt.Sprintf("Collections in '%s' (%d):", alias, len(collections)))
+			}
+			for _, c := range collections {
+				line := fmt.Sprintf("%s (%d secrets)", c.Name, c.SecretCount)
+				if c.Description != "" {
+					line += " - " + c.Description
+				}
+
+				b.Node(renderers.ItemNode).
+					Attr("name", c.Name).
+					Attr("description", c.Description).
+					Attr("secret_count", c.SecretCount).
+					Content(line).
+					Up()
+			}
+			b.Up()
+
+			if len(collections) == 0 {
+				b.Node(renderers.MessageNode).Content(fmt.Sprintf("Vault '%s' has no collections", alias)).Up()
+			}
+			return nil
+		})
+	})
+
+	return b.Build(), err
+}
+
+func CollectionRenameHandler(target, newName string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		ref, err := workspace.ParseCollectionReference(target)
+		if err != nil {
+			return err
+		}
+
+		err = withVault(ws, ref.Vault, func(v *vault.Vault) error {
+			return v.RenameCollection(ref.Collection, newName)
+		})
+		if err != nil {
+			return err
+		}
+
+		addCollectionReference(b, ref).
+			Attr("new_name", newName).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Renamed collection '%s' to '%s'", ref, newName)).Up()
+		return warnStaleReferences(ws, ref)
+	})
+
+	return b.Build(), err
+}
+
+func CollectionDescribeHandler(target, description string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		ref, err := workspace.ParseCollectionReference(target)
+		if err != nil {
+			return err
+		}
+
+		err = withVault(ws, ref.Vault, func(v *vault.Vault) error {
+			return v.DescribeCollection(ref.Collection, description)
+		})
+		if err != nil {
+			return err
+		}
+
+		addCollectionReference(b, ref).
+			Attr("description", description).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Updated description of collection '%s'", ref)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+func CollectionRemoveHandler(target string, force bool) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		ref, err := workspace.ParseCollectionReference(target)
+		if err != nil {
+			return err
+		}
+
+		err = withVault(ws, ref.Vault, func(v *vault.Vault) error {
+			return withSnapshot(ws, b, "collection rm "+ref.String(), captureVault(ws, ref.Vault, v), func() error {
+				return v.DeleteCollection(ref.Collection, force)
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		addCollectionReference(b, ref).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Removed collection '%s'", ref)).Up()
+		return warnStaleReferences(ws, ref)
+	})
+
+	return b.Build(), err
+}
+
+// addCollectionReference sets the fields identifying ref on the current node
+func addCollectionReference(b ast.Builder, ref *workspace.CollectionReference) ast.Builder {
+	return b.Attr("vault", ref.Vault).Attr("collection", ref.Collection)
+}
+
+// warnStaleReferences warns about project mappings still pointing at a collection
+// that no longer exists under its old name
+func warnStaleReferences(ws *workspace.Workspace, ref *workspace.CollectionReference) error {
+	refs, err := ws.ReferencesTo(ref.Vault, ref.Collection)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range refs {
+		common.Warn("project '%s' secret '%s' still references '%s'", r.Project, r.Name, r.Reference)
+	}
+	return nil
+}
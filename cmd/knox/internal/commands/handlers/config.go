@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+)
+
+func ConfigListHandler() (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		values, err := ws.ListConfig()
+		if err != nil {
+			return err
+		}
+
+		b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "settings")
+		for _, value := range values {
+			line := fmt.Sprintf("%s = %s", value.Key, value.Value)
+			if !value.IsSet {
+				line += " (default)"
+			}
+
+			addConfigValue(b.Node(renderers.ItemNode), value).
+				Attr("description", value.Description).
+				Content(line).
+				Up()
+		}
+		b.Up()
+
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+func ConfigGetHandler(key string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		value, err := ws.GetConfigValue(key)
+		if err != nil {
+			return err
+		}
+
+		addConfigValue(b, value).
+			Node(renderers.MessageNode).Content(value.Value).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+func ConfigSetHandler(key, value string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		if err := ws.SetConfig(key, value); err != nil {
+			return err
+		}
+
+		b.Attr("key", key).Attr("value", value).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Set %s = %s", key, value)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+func ConfigUnsetHandler(key string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		if err := ws.UnsetConfig(key); err != nil {
+			return err
+		}
+
+		value, err := ws.GetConfigValue(key)
+		if err != nil {
+			return err
+		}
+
+		addConfigValue(b, value).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Unset %s (default: %s)", key, value.Value)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+// addConfigValue sets the fields describing value on the current node
+func addConfigValue(b ast.Builder, value workspace.ConfigValue) ast.Builder {
+	return b.Attr("key", value.Key).
+		Attr("value", value.Value).
+		Attr("type", string(value.Type)).
+		Attr("default", value.DefaultValue).
+		Attr("set", value.IsSet)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// DoctorHandler diagnoses the knox environment and the local workspace, and
+// applies the fixes it can when fix is set. It works without a workspace, or
+// with one too broken to open. healthy is false while errors remain.
+func DoctorHandler(fix bool) (n ast.Node, healthy bool, err error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, false, errs.Wrap(err, error_codes.SearchFailureErrCode, "failed to get working directory")
+	}
+
+	// A missing workspace is reported as a finding
+	dir, _ := workspace.FindWorkspaceDir(cwd)
+
+	findings := workspace.Diagnose(dir)
+	if fix {
+		workspace.Repair(findings)
+	}
+
+	b := ast.NewBuilder(renderers.ResultNode)
+	b.Attr("workspace", dir).Attr("fix", fix)
+
+	counts := make(map[workspace.Severity]int)
+	fixed, fixable := 0, 0
+
+	b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "findings")
+	for _, f := range findings {
+		if !f.Resolved() {
+			counts[f.Severity]++
+			if f.CanFix() {
+				fixable++
+			}
+		}
+		if f.Fixed {
+			fixed++
+		}
+
+		b.Node(renderers.ItemNode).
+			Attr("subject", f.Subject).
+			Attr("severity", f.Severity).
+			Attr("problem", f.Problem).
+			Attr("suggestion", f.Suggestion).
+			Attr("fixable", f.CanFix()).
+			Attr("fixed", f.Fixed).
+			Content(fmt.Sprintf("[%s] %s: %s", f.Severity, f.Subject, f.Problem))
+
+		switch {
+		case f.Fixed:
+			b.Node(renderers.MessageNode).Content("fixed").Up()
+		case f.FixErr != nil:
+			b.Attr("fix_error", f.FixErr.Error()).
+				Node(renderers.MessageNode).Content(fmt.Sprintf("fix failed: %v", f.FixErr)).Up()
+			fallthrough
+		case f.Suggestion != "":
+			b.Node(renderers.MessageNode).Content("suggestion: " + f.Suggestion).Up()
+		}
+		b.Up()
+	}
+	b.Up()
+
+	errors, warnings := counts[workspace.SeverityError], counts[workspace.SeverityWarning]
+	healthy = errors == 0
+	b.Attr("errors", errors).Attr("warnings", warnings).Attr("fixed", fixed).Attr("healthy", healthy)
+
+	summary := "No problems found"
+	if errors+warnings > 0 {
+		summary = fmt.Sprintf("%d errors, %d warnings", errors, warnings)
+	}
+	if fixed > 0 {
+		summary += fmt.Sprintf(", %d fixed", fixed)
+	}
+	b.Node(renderers.MessageNode).Content(summary).Up()
+
+	if fixable > 0 && !fix {
+		b.Node(renderers.MessageNode).Content(fmt.Sprintf("Run 'knox doctor --fix' to fix %d of them", fixable)).Up()
+	}
+
+	return b.Build(), healthy, nil
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/envexport"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/resolver"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/tomdoesdev/knox/kit/fs"
+)
+
+// ExportHandler resolves the project's secrets and encodes them in format.
+// The encoded environment is written as is to w, or to out with 0600
+// permissions when out is set. An existing out is only replaced when force is
+// set. Only writing to out produces a result to render, since the export
+// itself must not be wrapped in an output format.
+func ExportHandler(projectName, format, out string, force bool, w io.Writer) (ast.Node, error) {
+	var n ast.Node
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		if out != "" && !force && fs.IsExist(out) {
+			return errs.New(error_codes.ValidationErrCode, "export file already exists, use --force to replace it").WithPath(out)
+		}
+
+		project, err := loadProject(ws, projectName)
+		if err != nil {
+			return err
+		}
+
+		values, err := resolver.New(ws, common.UnlockVault).Resolve(project)
+		if err != nil {
+			return err
+		}
+
+		data, err := envexport.Encode(format, values)
+		if err != nil {
+			return err
+		}
+
+		if out == "" {
+			if _, err := w.Write(data); err != nil {
+				return errs.Wrap(err, error_codes.FilePermissionErrCode, "failed to write export")
+			}
+			return nil
+		}
+
+		if err := fs.WriteFileAtomic(out, data, 0600); err != nil {
+			return errs.Wrap(err, error_codes.FilePermissionErrCode, "failed to write export file").WithPath(out)
+		}
+
+		n = ast.NewBuilder(renderers.ResultNode).
+			Attr("project", project.Name).
+			Attr("format", format).
+			Attr("count", len(values)).
+			Attr("path", out).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Exported %d secrets from '%s' to %s", len(values), project.Name, out)).Up().
+			Build()
+		return nil
+	})
+
+	return n, err
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tomdoesdev/knox/internal/envexport"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+func TestExportHandler(t *testing.T) {
+	newTestWorkspace(t)
+
+	_, err := SetSecretHandler("", "", "API_KEY", "s3cret", false)
+	errs.AssertNoError(t, err)
+
+	var stdout bytes.Buffer
+	n, err := ExportHandler("", envexport.DotenvFormat, "", false, &stdout)
+	errs.AssertNoError(t, err)
+	if n != nil {
+		t.Error("ExportHandler() to stdout returned a result to render")
+	}
+	if got := stdout.String(); got != "API_KEY=\"s3cret\"\n" {
+		t.Errorf("stdout = %q, want %q", got, "API_KEY=\"s3cret\"\n")
+	}
+
+	out := filepath.Join(t.TempDir(), "out.env")
+	if err := os.WriteFile(out, []byte("KEEP=1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ExportHandler("", envexport.DotenvFormat, out, false, &stdout)
+	assertCauseCode(t, err, error_codes.ValidationErrCode)
+	if data, _ := os.ReadFile(out); string(data) != "KEEP=1\n" {
+		t.Errorf("existing file was changed to %q without --force", data)
+	}
+
+	_, err = ExportHandler("", envexport.DotenvFormat, out, true, &stdout)
+	errs.AssertNoError(t, err)
+	if data, _ := os.ReadFile(out); string(data) != "API_KEY=\"s3cret\"\n" {
+		t.Errorf("file = %q after --force, want the export", data)
+	}
+}
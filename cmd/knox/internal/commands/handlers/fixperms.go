@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// Actions taken by knox fix-perms
+const (
+	permsTightened = "tightened"
+	permsPending   = "pending"
+	permsSkipped   = "skipped"
+)
+
+// FixPermsHandler removes group and world access from the workspace, its
+// project files and linked vaults. Paths owned by another user are only
+// reported, since changing their owner needs more privileges than knox has.
+func FixPermsHandler(dryRun bool) (ast.Node, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.SearchFailureErrCode, "failed to get working directory")
+	}
+
+	dir, err := workspace.FindWorkspaceDir(cwd)
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.SearchFailureErrCode, "workspace operation failed")
+	}
+
+	// The workspace is not opened, so permissions can be fixed even when
+	// opening it is refused
+	paths, err := workspace.PrivatePaths(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	b := ast.NewBuilder(renderers.ResultNode)
+	counts := make(map[string]int)
+
+	b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "paths")
+	for _, p := range paths {
+		v, err := p.Policy.Check(p.Path)
+		if err != nil {
+			common.Warn("failed to check %s: %v", p.Path, err)
+			continue
+		}
+		if v == nil {
+			continue
+		}
+
+		action := permsPending
+		switch {
+		case v.Foreign:
+			action = permsSkipped
+		case !dryRun:
+			if _, err := p.Policy.Enforce(p.Path); err != nil {
+				return nil, err
+			}
+			action = permsTightened
+		}
+		counts[action]++
+
+		line := fmt.Sprintf("%s %s: %04o -> %04o", p.Kind, p.Path, v.Mode, v.Mode&^v.Excess)
+		if v.Foreign {
+			line = fmt.Sprintf("%s %s: owned by uid %d, change its owner by hand", p.Kind, p.Path, v.UID)
+		}
+
+		b.Node(renderers.ItemNode).
+			Attr("kind", p.Kind).
+			Attr("path", p.Path).
+			Attr("mode", fmt.Sprintf("%04o", v.Mode)).
+			Attr("uid", v.UID).
+			Attr("action", action).
+			Content(line).
+			Up()
+	}
+	b.Up()
+
+	b.Attr("checked", len(paths)).
+		Attr("tightened", counts[permsTightened]).
+		Attr("pending", counts[permsPending]).
+		Attr("skipped", counts[permsSkipped]).
+		Attr("dry_run", dryRun)
+
+	var summary string
+	switch {
+	case counts[permsTightened]+counts[permsPending]+counts[permsSkipped] == 0:
+		summary = fmt.Sprintf("All %d paths are private", len(paths))
+	case dryRun:
+		summary = fmt.Sprintf("Dry run: %d paths would be tightened", counts[permsPending])
+	default:
+		summary = fmt.Sprintf("Tightened %d paths", counts[permsTightened])
+	}
+	if counts[permsSkipped] > 0 {
+		summary += fmt.Sprintf(", %d owned by another user", counts[permsSkipped])
+	}
+	b.Node(renderers.MessageNode).Content(summary).Up()
+
+	return b.Build(), nil
+}
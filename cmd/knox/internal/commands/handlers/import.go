@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/dotenv"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// Conflict policies for keys that already exist in the target collection
+const (
+	ConflictFail      = "fail"
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+)
+
+// Changes an import makes to a single key
+const (
+	importAdd       = "add"
+	importUpdate    = "update"
+	importSkip      = "skip"
+	importUnchanged = "unchanged"
+)
+
+// importMarkers prefix each key in the text preview
+var importMarkers = map[string]string{
+	importAdd:       "+",
+	importUpdate:    "~",
+	importSkip:      "!",
+	importUnchanged: "=",
+}
+
+// ImportDotenvOptions configures an import of a dotenv file into a collection
+type ImportDotenvOptions struct {
+	Path   string
+	Target string
+	// Conflict is one of ConflictFail, ConflictSkip or ConflictOverwrite
+	Conflict string
+	// DryRun only previews the import
+	DryRun bool
+	// Yes imports without asking for confirmation
+	Yes bool
+	// Confirm is shown the preview and decides whether to go ahead. A nil
+	// Confirm means no one can be asked, so imports then need Yes.
+	Confirm func(preview ast.Node) (bool, error)
+}
+
+type importChange struct {
+	Key    string
+	Value  string
+	Line   int
+	Action string
+}
+
+func ImportDotenvHandler(opts ImportDotenvOptions) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	entries, err := readDotenvFile(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		ref, err := workspace.ParseCollectionReference(opts.Target)
+		if err != nil {
+			return err
+		}
+
+		return withUnlockedVault(ws, ref.Vault, func(v *vault.Vault) error {
+			changes, err := planImport(v, ref.Collection, entries, opts.Conflict)
+			if err != nil {
+				return err
+			}
+
+			b.Attr("file", opts.Path).Attr("vault", ref.Vault).Attr("collection", ref.Collection)
+			addImportChanges(b, changes, ref.String())
+
+			counts := make(map[string]int)
+			for _, c := range changes {
+				counts[c.Action]++
+			}
+			writes := counts[importAdd] + counts[importUpdate]
+
+			applied := false
+			defer func() {
+				b.Attr("applied", applied).
+					Attr("added", counts[importAdd]).
+					Attr("updated", counts[importUpdate]).
+					Attr("skipped", counts[importSkip]).
+					Attr("unchanged", counts[importUnchanged])
+			}()
+
+			switch {
+			case writes == 0:
+				b.Node(renderers.MessageNode).Content(fmt.Sprintf("Nothing to import into '%s'", ref)).Up()
+				return nil
+			case opts.DryRun:
+				b.Node(renderers.MessageNode).Content("Dry run, nothing was imported").Up()
+				return nil
+			case !opts.Yes:
+				if opts.Confirm == nil {
+					return errs.New(error_codes.ValidationErrCode, "cannot confirm import, pass --yes or run in a terminal").
+						WithContext("file", opts.Path)
+				}
+
+				preview := ast.NewBuilder(renderers.ResultNode)
+				addImportChanges(preview, changes, ref.String())
+				ok, err := opts.Confirm(preview.Build())
+				if err != nil {
+					return err
+				}
+				if !ok {
+					b.Node(renderers.MessageNode).Content("Import cancelled").Up()
+					return nil
+				}
+			}
+
+			for _, c := range changes {
+				if c.Action != importAdd && c.Action != importUpdate {
+					continue
+				}
+				if err := v.SetSecret(ref.Collection, c.Key, c.Value, true); err != nil {
+					return errs.Wrap(err, error_codes.SecretInvalidErrCode, "failed to import secret").
+						WithContext("key", c.Key).
+						WithContext("line", c.Line)
+				}
+			}
+			applied = true
+
+			b.Node(renderers.MessageNode).Content(fmt.Sprintf("Imported %d secrets into '%s' (%d added, %d updated, %d skipped, %d unchanged)",
+				writes, ref, counts[importAdd], counts[importUpdate], counts[importSkip], counts[importUnchanged])).Up()
+			return nil
+		})
+	})
+
+	return b.Build(), err
+}
+
+// readDotenvFile parses the dotenv file at path, warning about keys that are
+// assigned more than once. The last assignment of a key wins.
+func readDotenvFile(path string) ([]dotenv.Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.FileNotFoundErrCode, "failed to open dotenv file").WithContext("path", path)
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	entries, err := dotenv.Parse(f)
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.ValidationErrCode, "failed to parse dotenv file").WithContext("path", path)
+	}
+
+	last := make(map[string]int, len(entries))
+	for i, e := range entries {
+		if prev, seen := last[e.Key]; seen {
+			common.Warn("'%s' is assigned on lines %d and %d, using line %d", e.Key, entries[prev].Line, e.Line, e.Line)
+		}
+		last[e.Key] = i
+	}
+
+	var unique []dotenv.Entry
+	for i, e := range entries {
+		if last[e.Key] == i {
+			unique = append(unique, e)
+		}
+	}
+
+	return unique, nil
+}
+
+// planImport decides what happens to each entry. Under ConflictFail, nothing
+// is planned when any key already holds a different value.
+func planImport(v *vault.Vault, collection string, entries []dotenv.Entry, conflict string) ([]importChange, error) {
+	if _, err := v.GetCollection(collection); err != nil {
+		return nil, err
+	}
+
+	var conflicts []string
+	changes := make([]importChange, 0, len(entries))
+	for _, e := range entries {
+		change := importChange{Key: e.Key, Value: e.Value, Line: e.Line, Action: importAdd}
+
+		existing, err := v.GetSecret(collection, e.Key)
+		switch {
+		case errs.Is(err, error_codes.SecretNotFoundErrCode):
+		case err != nil:
+			return nil, err
+		case existing.Value == e.Value:
+			change.Action = importUnchanged
+		case conflict == ConflictOverwrite:
+			change.Action = importUpdate
+		case conflict == ConflictSkip:
+			change.Action = importSkip
+		default:
+			conflicts = append(conflicts, e.Key)
+		}
+
+		changes = append(changes, change)
+	}
+
+	if len(conflicts) > 0 {
+		return nil, errs.New(error_codes.SecretExistsErrCode, "secrets already exist with different values, use --skip or --overwrite").
+			WithContext("collection", collection).
+			WithContext("keys", strings.Join(conflicts, ", "))
+	}
+
+	return changes, nil
+}
+
+// addImportChanges adds the list of planned changes to the current node. Values
+// are never included.
+func addImportChanges(b ast.Builder, changes []importChange, target string) {
+	b.Node(renderers.ListNode).
+		Attr(renderers.FieldAttr, "changes").
+		Content(fmt.Sprintf("Import into '%s' (%d keys):", target, len(changes)))
+	for _, c := range changes {
+		b.Node(renderers.ItemNode).
+			Attr("key", c.Key).
+			Attr("line", c.Line).
+			Attr("action", c.Action).
+			Content(fmt.Sprintf("%s %s", importMarkers[c.Action], c.Key)).
+			Up()
+	}
+	b.Up()
+}
@@ -4,12 +4,13 @@ import (
 	"fmt"
 
 	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
 	"github.com/tomdoesdev/knox/internal/workspace"
 	"github.com/tomdoesdev/knox/kit/ast"
 )
 
 func InitHandler() (ast.Node, error) {
-	b := ast.NewBuilder("result")
+	b := ast.NewBuilder(renderers.ResultNode)
 
 	err := common.WithEnsuredLocalWorkspace(func(ws *workspace.Workspace, result workspace.InitResult) error {
 		currentProject, err := ws.CurrentProject()
@@ -17,33 +18,21 @@ func InitHandler() (ast.Node, error) {
 			currentProject = "none"
 		}
 
-		// Set path and project attributes
+		b.Attr("path", ws.Dir()).Attr("project", currentProject)
 
 		switch result {
 		case workspace.Created:
-			{
-				b.Node("message").Attr("path", ws.Dir()).Attr("project", currentProject).
-					Attr("created", true).
-					Content(fmt.Sprintf("initialized empty workspace in %s", ws.Dir())).
-					Up().
-					Node("message").
-					Content(fmt.Sprintf("current project: %s\n", currentProject))
-			}
-			break
+			b.Attr("created", true).
+				Node(renderers.MessageNode).Content(fmt.Sprintf("initialized empty workspace in %s", ws.Dir())).Up()
 		case workspace.Existed:
-
-			b.Node("message").Attr("path", ws.Dir()).Attr("project", currentProject).
-				Attr("created", true).
-				Node("message").
-				Content(fmt.Sprintf("workspace already exists in %s\n", ws.Dir())).
-				Up().
-				Node("message").
-				Content(fmt.Sprintf("current project: %s\n", currentProject))
-			break
+			b.Attr("created", false).
+				Node(renderers.MessageNode).Content(fmt.Sprintf("workspace already exists in %s", ws.Dir())).Up()
 		default:
 			panic(fmt.Sprintf("unexpected result: %s", result))
 		}
 
+		b.Node(renderers.MessageNode).Content(fmt.Sprintf("current project: %s", currentProject)).Up()
+
 		return nil
 	})
 
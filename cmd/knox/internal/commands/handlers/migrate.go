@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+func MigrateHandler(dryRun bool) (ast.Node, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.SearchFailureErrCode, "failed to get working directory")
+	}
+
+	dir, err := workspace.FindWorkspaceDir(cwd)
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.SearchFailureErrCode, "workspace operation failed")
+	}
+
+	// Inspect before opening anything, since opening applies migrations
+	reports, err := workspace.InspectSchemas(dir)
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to inspect schemas")
+	}
+
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "schemas")
+	pending := 0
+	for _, report := range reports {
+		addSchemaReport(b, report)
+		pending += len(report.Status.Pending)
+	}
+	b.Up()
+
+	b.Attr("pending", pending).Attr("dry_run", dryRun)
+
+	if pending == 0 {
+		b.Attr("applied", 0).
+			Node(renderers.MessageNode).Content("All schemas are up to date").Up()
+		return b.Build(), nil
+	}
+	if dryRun {
+		b.Attr("applied", 0).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Dry run: %d pending migrations not applied", pending)).Up()
+		return b.Build(), nil
+	}
+
+	err = common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		// Opening the workspace has migrated its database, opening each vault migrates it
+		applied := len(reports[0].Status.Pending)
+		for _, report := range reports[1:] {
+			if report.Err != nil || report.Status.UpToDate() {
+				continue
+			}
+
+			v, err := ws.OpenVault(report.Name)
+			if err != nil {
+				common.Warn("failed to migrate vault '%s': %v", report.Name, err)
+				continue
+			}
+			_ = v.Close()
+
+			applied += len(report.Status.Pending)
+		}
+
+		b.Attr("applied", applied).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Applied %d of %d pending migrations", applied, pending)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+// addSchemaReport adds report as an item of the current list
+func addSchemaReport(b ast.Builder, report workspace.SchemaReport) {
+	b.Node(renderers.ItemNode).Attr("name", report.Name).Attr("path", report.Path)
+
+	if report.Err != nil {
+		b.Attr("error", report.Err.Error()).
+			Content(fmt.Sprintf("%s: %v", report.Name, report.Err)).
+			Up()
+		return
+	}
+
+	status := report.Status
+	b.Attr("current", status.Current).Attr("latest", status.Latest)
+
+	if status.UpToDate() {
+		b.Content(fmt.Sprintf("%s: version %d (up to date)", report.Name, status.Current))
+	} else {
+		b.Content(fmt.Sprintf("%s: version %d -> %d", report.Name, status.Current, status.Latest))
+	}
+
+	b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "pending")
+	for _, migration := range status.Pending {
+		b.Node(renderers.ItemNode).
+			Attr("version", migration.Version).
+			Attr("description", migration.Description).
+			Content(fmt.Sprintf("%d: %s", migration.Version, migration.Description)).
+			Up()
+	}
+	b.Up().Up()
+}
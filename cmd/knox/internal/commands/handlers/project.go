@@ -1,87 +1,107 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
 	"github.com/tomdoesdev/knox/internal/error_codes"
 	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
 	"github.com/tomdoesdev/knox/kit/errs"
 )
 
-func ProjectListHandler() error {
-	return common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+func ProjectListHandler() (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
 		projects, err := ws.ListProjects()
 		if err != nil {
 			return err
 		}
+		sort.Strings(projects)
 
-		if len(projects) == 0 {
-			fmt.Println("No projects found")
-			return nil
-		}
+		currentProject, _ := ws.CurrentProject()
 
-		fmt.Printf("Projects (%d):\n", len(projects))
+		b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "projects")
+		if len(projects) > 0 {
+			b.Content(fmt.Sprintf("Projects (%d):", len(projects)))
+		}
 		for _, project := range projects {
-			fmt.Printf("  %s\n", project)
+			b.Node(renderers.ItemNode).
+				Attr("name", project).
+				Attr("current", project == currentProject).
+				Content(project).
+				Up()
+		}
+		b.Up()
+
+		if len(projects) == 0 {
+			b.Node(renderers.MessageNode).Content("No projects found").Up()
 		}
 
 		return nil
 	})
 
+	return b.Build(), err
 }
 
-func ProjectDeleteHandler(name string) error {
-	return common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
-		err := ws.DeleteProject(name)
+func ProjectDeleteHandler(name string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		if _, err := ws.LoadProject(name); err != nil {
+			return err
+		}
+
+		err := withSnapshot(ws, b, "project delete "+name, captureProjects(ws, name), func() error {
+			return ws.DeleteProject(name)
+		})
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("Deleted project '%s'\n", name)
+		b.Attr("name", name).Attr("deleted", true).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Deleted project '%s'", name)).Up()
 		return nil
 	})
-}
 
-func ProjectListSecretsHandler(projectName string) error {
+	return b.Build(), err
+}
 
-	return common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+func ProjectListSecretsHandler(projectName string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
 
-		// If no project name provided, try to get current project
-		if projectName == "" {
-			// TODO: Get current project from workspace settings
-			return errs.New(error_codes.ValidationErrCode, "project name is required (current project tracking not yet implemented)")
-		}
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
 
-		project, err := ws.LoadProject(projectName)
+		project, err := loadProject(ws, projectName)
 		if err != nil {
 			return err
 		}
 
-		secrets := project.ListSecrets()
-		if len(secrets) == 0 {
-			fmt.Printf("Project '%s' has no secrets\n", projectName)
-			return nil
-		}
-
-		fmt.Printf("Secrets in project '%s' (%d):\n", projectName, len(secrets))
-		for _, logicalName := range secrets {
-			secretRef, _ := project.GetSecret(logicalName)
-			fmt.Printf("  %s -> %s\n", logicalName, secretRef)
-		}
-
+		addProjectSecrets(b, project)
 		return nil
 	})
+
+	return b.Build(), err
 }
-func ProjectAddSecretHandler(projectName, logicalName, secretRef string) error {
-	return common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+func ProjectAddSecretHandler(projectName, logicalName, secretRef string, strict bool) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
 		// Validate secret reference format
-		_, err := workspace.ParseSecretReference(secretRef)
+		ref, err := workspace.ParseSecretReference(secretRef)
 		if err != nil {
 			return err
 		}
 
-		project, err := ws.LoadProject(projectName)
+		if _, err := ws.GetLinkedVault(ref.Vault); err != nil {
+			return err
+		}
+
+		project, err := loadProject(ws, projectName)
 		if err != nil {
 			return err
 		}
@@ -91,6 +111,11 @@ func ProjectAddSecretHandler(projectName, logicalName, secretRef string) error {
 			return errs.New(error_codes.SecretExistsErrCode, "secret already exists in project").WithContext("logical_name", logicalName)
 		}
 
+		problems := ws.CheckSecretReferences(map[string]string{logicalName: secretRef})
+		if err := reportReferenceProblems(problems, strict); err != nil {
+			return err
+		}
+
 		project.AddSecret(logicalName, secretRef)
 
 		err = ws.UpdateProject(project)
@@ -98,15 +123,19 @@ func ProjectAddSecretHandler(projectName, logicalName, secretRef string) error {
 			return err
 		}
 
-		fmt.Printf("Added secret '%s' -> '%s' to project '%s'\n", logicalName, secretRef, projectName)
+		b.Attr("project", project.Name).Attr("name", logicalName).Attr("reference", secretRef).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Added secret '%s' -> '%s' to project '%s'", logicalName, secretRef, project.Name)).Up()
 		return nil
 	})
+
+	return b.Build(), err
 }
 
-func ProjectRemoveSecretHandler(projectName, logicalName string) error {
+func ProjectRemoveSecretHandler(projectName, logicalName string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
 
-	return common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
-		project, err := ws.LoadProject(projectName)
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		project, err := loadProject(ws, projectName)
 		if err != nil {
 			return err
 		}
@@ -118,12 +147,109 @@ func ProjectRemoveSecretHandler(projectName, logicalName string) error {
 
 		project.RemoveSecret(logicalName)
 
-		err = ws.UpdateProject(project)
+		err = withSnapshot(ws, b, "project remove-secret "+logicalName, captureProjects(ws, project.Name), func() error {
+			return ws.UpdateProject(project)
+		})
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("Removed secret '%s' from project '%s'\n", logicalName, projectName)
+		b.Attr("project", project.Name).Attr("name", logicalName).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Removed secret '%s' from project '%s'", logicalName, project.Name)).Up()
 		return nil
 	})
+
+	return b.Build(), err
+}
+
+func ProjectValidateHandler(projectName string, strict bool) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		project, err := loadProject(ws, projectName)
+		if err != nil {
+			return err
+		}
+
+		if err := project.Validate(); err != nil {
+			return err
+		}
+
+		problems := ws.CheckSecretReferences(project.SecretMap)
+		if err := reportReferenceProblems(problems, strict); err != nil {
+			return err
+		}
+
+		b.Attr("project", project.Name).Attr("valid", len(problems) == 0)
+
+		b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "problems")
+		for _, p := range problems {
+			b.Node(renderers.ItemNode).
+				Attr("name", p.Name).
+				Attr("reference", p.Reference).
+				Attr("error", p.Err.Error()).
+				Up()
+		}
+		b.Up()
+
+		if len(problems) > 0 {
+			b.Node(renderers.MessageNode).Content(fmt.Sprintf("Project '%s' has %d unresolvable secret references", project.Name, len(problems))).Up()
+			return nil
+		}
+
+		b.Node(renderers.MessageNode).Content(fmt.Sprintf("Project '%s' is valid", project.Name)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+// reportReferenceProblems prints problems as warnings, or returns them as a
+// single error when strict is set
+func reportReferenceProblems(problems []workspace.ReferenceProblem, strict bool) error {
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if !strict {
+		for _, p := range problems {
+			common.Warn("'%s' -> '%s': %v", p.Name, p.Reference, p.Err)
+		}
+		return nil
+	}
+
+	causes := make([]error, 0, len(problems))
+	for _, p := range problems {
+		causes = append(causes, errs.Wrap(p.Err, error_codes.ProjectInvalidErrCode, "'%s' -> '%s'", p.Name, p.Reference))
+	}
+
+	return errs.Wrap(errors.Join(causes...), error_codes.ProjectInvalidErrCode, "secret references do not resolve").
+		WithContext("count", len(problems))
+}
+
+// addProjectSecrets adds the secret map of project to the result as a list of
+// logical names and references
+func addProjectSecrets(b ast.Builder, project *workspace.Project) {
+	names := project.ListSecrets()
+	sort.Strings(names)
+
+	b.Attr("project", project.Name)
+
+	b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "secrets")
+	if len(names) > 0 {
+		b.Content(fmt.Sprintf("Secrets in project '%s' (%d):", project.Name, len(names)))
+	}
+	for _, name := range names {
+		secretRef, _ := project.GetSecret(name)
+		b.Node(renderers.ItemNode).
+			Attr("name", name).
+			Attr("reference", secretRef).
+			Content(fmt.Sprintf("%s -> %s", name, secretRef)).
+			Up()
+	}
+	b.Up()
+
+	if len(names) == 0 {
+		b.Node(renderers.MessageNode).Content(fmt.Sprintf("Project '%s' has no secrets", project.Name)).Up()
+	}
 }
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// RelinkHandler points an existing vault link at a new path. The vault found
+// there must carry the id recorded on the link unless force is set. With
+// relative set, the path is stored in a portable form.
+func RelinkHandler(alias, vaultPath string, force, relative bool) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		linked, err := ws.GetLinkedVault(alias)
+		if err != nil {
+			return err
+		}
+
+		absPath, err := filepath.Abs(vaultPath)
+		if err != nil {
+			return errs.Wrap(err, error_codes.ValidationErrCode, "failed to resolve vault path").WithContext("path", vaultPath)
+		}
+
+		vaultID, err := vault.ReadID(absPath)
+		if err != nil {
+			return errs.Wrap(err, error_codes.VaultConnectionErrCode, "no valid vault found at path").WithContext("path", absPath)
+		}
+
+		if linked.VaultID != "" && vaultID != linked.VaultID {
+			if !force {
+				return errs.New(error_codes.VaultIntegrityErrCode, "vault at path is not the linked vault, use --force to relink anyway").
+					WithContext("alias", alias).
+					WithContext("linked_id", linked.VaultID).
+					WithContext("found_id", vaultID)
+			}
+			common.Warn("relinking '%s' to a different vault (%s)", alias, vaultID)
+		}
+
+		storedPath := absPath
+		if relative {
+			storedPath, err = ws.PortableVaultPath(absPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := ws.SetLinkedVaultPath(alias, storedPath); err != nil {
+			return err
+		}
+
+		b.Attr("alias", alias).
+			Attr("path", absPath).
+			Attr("stored_path", storedPath).
+			Attr("previous_path", linked.Path).
+			Attr("vault_id", vaultID).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Relinked vault '%s' to %s", alias, storedPath)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/envtemplate"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/resolver"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/tomdoesdev/knox/kit/fs"
+)
+
+// DefaultTemplatePath is the template rendered when no path is given
+const DefaultTemplatePath = ".env.template"
+
+// RenderHandler renders the template at templatePath with the project's
+// secrets and writes the result as is to w, or to out with 0600 permissions
+// when out is set. Only writing to out produces a result to render, since the
+// rendered file must not be wrapped in an output format.
+func RenderHandler(projectName, templatePath, out string, w io.Writer) (ast.Node, error) {
+	var n ast.Node
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		renderer, text, err := loadTemplate(ws, projectName, templatePath)
+		if err != nil {
+			return err
+		}
+
+		rendered, err := renderer.Render(templatePath, text)
+		if err != nil {
+			return err
+		}
+
+		if out == "" {
+			if _, err := io.WriteString(w, rendered); err != nil {
+				return errs.Wrap(err, error_codes.FilePermissionErrCode, "failed to write rendered template")
+			}
+			return nil
+		}
+
+		if err := fs.WriteFileAtomic(out, []byte(rendered), 0600); err != nil {
+			return errs.Wrap(err, error_codes.FilePermissionErrCode, "failed to write rendered template").WithPath(out)
+		}
+
+		n = ast.NewBuilder(renderers.ResultNode).
+			Attr("template", templatePath).
+			Attr("path", out).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Rendered %s to %s", templatePath, out)).Up().
+			Build()
+		return nil
+	})
+
+	return n, err
+}
+
+// loadTemplate reads the template at path and prepares a renderer for the
+// project's secrets. Secrets that fail to resolve only cause an error if the
+// template uses them.
+func loadTemplate(ws *workspace.Workspace, projectName, path string) (*envtemplate.Renderer, string, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", errs.Wrap(err, error_codes.FileNotFoundErrCode, "failed to read template").WithPath(path)
+	}
+
+	project, err := loadProject(ws, projectName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	values, err := resolver.New(ws, common.UnlockVault).Resolve(project)
+
+	if err != nil && !errs.Is(err, error_codes.ResolutionFailureErrCode) {
+		return nil, "", err
+	}
+
+	unresolved := make(map[string]error)
+	var problems resolver.Problems
+	if errors.As(err, &problems) {
+		for _, problem := range problems {
+			for _, name := range problem.Names {
+				unresolved[name] = problem.Err
+			}
+		}
+	}
+
+	return envtemplate.New(values, unresolved), string(text), nil
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/internal/resolver"
+	"github.com/tomdoesdev/knox/internal/runner"
+	"github.com/tomdoesdev/knox/internal/workspace"
+)
+
+// RunHandler resolves the project's secrets and runs argv with them in its
+// environment, returning the child's exit code. When templatePath is set the
+// child receives the variables rendered from that template instead of the
+// project's secret map. The workspace is closed before the child starts.
+func RunHandler(projectName, templatePath string, allow []string, argv []string) (int, error) {
+	var env []string
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		values, err := runVars(ws, projectName, templatePath)
+		if err != nil {
+			return err
+		}
+
+		allowlist, err := ws.GetConfigList(workspace.RunEnvAllowlistSetting)
+		if err != nil {
+			return err
+		}
+
+		env = runner.BuildEnv(values, append(allowlist, allow...))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return runner.Run(argv, env)
+}
+
+// runVars returns the variables to inject into the child process
+func runVars(ws *workspace.Workspace, projectName, templatePath string) (map[string]string, error) {
+	if templatePath != "" {
+		renderer, text, err := loadTemplate(ws, projectName, templatePath)
+		if err != nil {
+			return nil, err
+		}
+
+		return renderer.RenderVars(templatePath, text)
+	}
+
+	project, err := loadProject(ws, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolver.New(ws, common.UnlockVault).Resolve(project)
+}
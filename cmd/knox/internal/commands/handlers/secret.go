@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// secretLocation identifies a single secret inside a linked vault
+type secretLocation struct {
+	Vault      string
+	Collection string
+	Key        string
+	Version    int
+}
+
+func (l *secretLocation) String() string {
+	if l.Version > 0 {
+		return fmt.Sprintf("%s@%s/%s#%d", l.Key, l.Vault, l.Collection, l.Version)
+	}
+	return fmt.Sprintf("%s@%s/%s", l.Key, l.Vault, l.Collection)
+}
+
+// expectUnpinned rejects writes through a reference pinned to a version
+func (l *secretLocation) expectUnpinned() error {
+	if l.Version > 0 {
+		return errs.New(error_codes.ValidationErrCode, "secret reference is pinned to a version and cannot be modified").
+			WithContext("reference", l.String())
+	}
+	return nil
+}
+
+func SetSecretHandler(projectName, target, key, value string, overwrite bool) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		location, err := resolveSecretLocation(ws, projectName, target, key)
+		if err != nil {
+			return err
+		}
+		if err := location.expectUnpinned(); err != nil {
+			return err
+		}
+
+		err = withUnlockedVault(ws, location.Vault, func(v *vault.Vault) error {
+			return v.SetSecret(location.Collection, location.Key, value, overwrite)
+		})
+		if err != nil {
+			return err
+		}
+
+		addSecretLocation(b, location).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Set secret '%s' (%s)", key, location)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+func GetSecretHandler(projectName, target, key string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		location, err := resolveSecretLocation(ws, projectName, target, key)
+		if err != nil {
+			return err
+		}
+
+		return withUnlockedVault(ws, location.Vault, func(v *vault.Vault) error {
+			secret, err := getLocatedSecret(v, location)
+			if err != nil {
+				return err
+			}
+
+			addSecretLocation(b, location).
+				Attr("version", secret.Version).
+				Attr("value", secret.Value).
+				Node(renderers.MessageNode).Content(secret.Value).Up()
+			return nil
+		})
+	})
+
+	return b.Build(), err
+}
+
+func RemoveSecretHandler(projectName, target, key string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		location, err := resolveSecretLocation(ws, projectName, target, key)
+		if err != nil {
+			return err
+		}
+		if err := location.expectUnpinned(); err != nil {
+			return err
+		}
+
+		err = withVault(ws, location.Vault, func(v *vault.Vault) error {
+			return withSnapshot(ws, b, "rm "+location.String(), captureVault(ws, location.Vault, v), func() error {
+				return v.DeleteSecret(location.Collection, location.Key)
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		addSecretLocation(b, location).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Removed secret '%s' (%s)", key, location)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+func ListSecretsHandler(projectName, target string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		if target == "" {
+			project, err := loadProject(ws, projectName)
+			if err != nil {
+				return err
+			}
+
+			addProjectSecrets(b, project)
+			return nil
+		}
+
+		ref, err := workspace.ParseCollectionReference(target)
+		if err != nil {
+			return err
+		}
+
+		return withVault(ws, ref.Vault, func(v *vault.Vault) error {
+			keys, err := v.ListSecretKeys(ref.Collection)
+			if err != nil {
+				return err
+			}
+
+			b.Attr("vault", ref.Vault).Attr("collection", ref.Collection)
+
+			b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "secrets")
+			if len(keys) > 0 {
+				b.Content(fmt.Sprintf("Secrets in '%s' (%d):", ref, len(keys)))
+			}
+			for _, key := range keys {
+				b.Node(renderers.ItemNode).Attr("key", key).Content(key).Up()
+			}
+			b.Up()
+
+			if len(keys) == 0 {
+				b.Node(renderers.MessageNode).Content(fmt.Sprintf("Collection '%s' has no secrets", ref)).Up()
+			}
+			return nil
+		})
+	})
+
+	return b.Build(), err
+}
+
+func HistoryHandler(projectName, target, key string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		location, err := resolveSecretLocation(ws, projectName, target, key)
+		if err != nil {
+			return err
+		}
+
+		return withVault(ws, location.Vault, func(v *vault.Vault) error {
+			history, err := v.SecretHistory(location.Collection, location.Key)
+			if err != nil {
+				return err
+			}
+
+			addSecretLocation(b, location).
+				Node(renderers.ListNode).
+				Attr(renderers.FieldAttr, "versions").
+				Content(fmt.Sprintf("History of '%s' (%d versions):", key, len(history)))
+			for _, version := range history {
+				marker := ""
+				if version.Current {
+					marker = " (current)"
+				}
+				b.Node(renderers.ItemNode).
+					Attr("version", version.Version).
+					Attr("created_at", version.CreatedAt).
+					Attr("current", version.Current).
+					Content(fmt.Sprintf("#%d  %s%s", version.Version, version.CreatedAt, marker)).
+					Up()
+			}
+			b.Up()
+			return nil
+		})
+	})
+
+	return b.Build(), err
+}
+
+func RollbackHandler(projectName, target, key string, version int) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		location, err := resolveSecretLocation(ws, projectName, target, key)
+		if err != nil {
+			return err
+		}
+		if err := location.expectUnpinned(); err != nil {
+			return err
+		}
+
+		var newVersion int
+		err = withVault(ws, location.Vault, func(v *vault.Vault) error {
+			newVersion, err = v.RollbackSecret(location.Collection, location.Key, version)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		addSecretLocation(b, location).
+			Attr("restored_version", version).
+			Attr("version", newVersion).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Rolled back '%s' (%s) to version %d as version %d", key, location, version, newVersion)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+// addSecretLocation sets the fields identifying location on the current node
+func addSecretLocation(b ast.Builder, location *secretLocation) ast.Builder {
+	return b.Attr("vault", location.Vault).
+		Attr("collection", location.Collection).
+		Attr("key", location.Key)
+}
+
+// getLocatedSecret reads the secret at location, honouring a pinned version
+func getLocatedSecret(v *vault.Vault, location *secretLocation) (*vault.Secret, error) {
+	if location.Version > 0 {
+		return v.GetSecretVersion(location.Collection, location.Key, location.Version)
+	}
+	return v.GetSecret(location.Collection, location.Key)
+}
+
+// resolveSecretLocation maps key onto a vault location. When target is empty,
+// key is treated as a logical name in the named project, or the current
+// project when projectName is empty.
+func resolveSecretLocation(ws *workspace.Workspace, projectName, target, key string) (*secretLocation, error) {
+	if target != "" {
+		ref, err := workspace.ParseCollectionReference(target)
+		if err != nil {
+			return nil, err
+		}
+
+		return &secretLocation{Vault: ref.Vault, Collection: ref.Collection, Key: key}, nil
+	}
+
+	project, err := loadProject(ws, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	secretRef, exists := project.GetSecret(key)
+	if !exists {
+		return nil, errs.New(error_codes.SecretNotFoundErrCode, fmt.Sprintf("secret is not mapped in project, pass --target collection@vault or map it with 'knox project add-secret %s <secret@vault/collection>'", key)).
+			WithContext("project", project.Name).
+			WithContext("logical_name", key)
+	}
+
+	ref, err := workspace.ParseSecretReference(secretRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return &secretLocation{Vault: ref.Vault, Collection: ref.Collection, Key: ref.Secret, Version: ref.Version}, nil
+}
+
+// loadProject loads the named project, or the workspace's current project when
+// name is empty. Commands fill name from --project or KNOX_PROJECT.
+func loadProject(ws *workspace.Workspace, name string) (*workspace.Project, error) {
+	if name != "" {
+		return ws.LoadProject(name)
+	}
+
+	name, err := ws.CurrentProject()
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.ProjectNotFoundErrCode, "no current project, run 'knox switch <project>' or pass --project")
+	}
+
+	return ws.LoadProject(name)
+}
+
+// withVault opens the vault linked under alias for the duration of fn
+func withVault(ws *workspace.Workspace, alias string, fn func(*vault.Vault) error) error {
+	v, err := ws.OpenVault(alias)
+	if err != nil {
+		return err
+	}
+	defer func(v *vault.Vault) {
+		_ = v.Close()
+	}(v)
+
+	return fn(v)
+}
+
+// withUnlockedVault opens and unlocks the vault linked under alias for the duration of fn
+func withUnlockedVault(ws *workspace.Workspace, alias string, fn func(*vault.Vault) error) error {
+	return withVault(ws, alias, func(v *vault.Vault) error {
+		if err := common.UnlockVault(alias, v); err != nil {
+			return err
+		}
+
+		return fn(v)
+	})
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+const testPassphrase = "test passphrase"
+
+// newTestWorkspace creates a workspace in a temporary directory and makes it
+// the working directory. An encrypted vault is linked as "v1", and API_KEY in
+// the default project maps to API_KEY@v1/global.
+func newTestWorkspace(t *testing.T) {
+	t.Helper()
+
+	t.Setenv(common.PassphraseEnvVar, testPassphrase)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	ws, err := workspace.CreateWorkspace(dir)
+	errs.AssertNoError(t, err)
+
+	vaultPath := filepath.Join(dir, "v1.db")
+	v, err := vault.Create(vaultPath, vault.CreateOptions{Passphrase: testPassphrase})
+	errs.AssertNoError(t, err)
+	errs.AssertNoError(t, v.Close())
+	errs.AssertNoError(t, ws.LinkVault("v1", vaultPath))
+
+	project, err := ws.LoadProject("default")
+	errs.AssertNoError(t, err)
+	project.AddSecret("API_KEY", "API_KEY@v1/global")
+	errs.AssertNoError(t, ws.UpdateProject(project))
+
+	t.Chdir(dir)
+}
+
+// assertCauseCode checks that err, or an error it wraps, has code. Handler
+// errors are wrapped by common.WithLocalWorkspace.
+func assertCauseCode(t *testing.T, err error, code errs.Code) {
+	t.Helper()
+
+	if err == nil {
+		t.Fatalf("Expected error with code %s, got nil", code)
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if structured, ok := e.(*errs.Error); ok && structured.Code == code {
+			return
+		}
+	}
+	t.Errorf("Expected error wrapping code %s, got %v", code, err)
+}
+
+// attr returns the string attribute key of n
+func attr(t *testing.T, n ast.Node, key string) string {
+	t.Helper()
+
+	a, ok := n.GetAttribute(key)
+	if !ok {
+		t.Fatalf("node has no %q attribute", key)
+	}
+	return a.AsStringOr("")
+}
+
+// listAttrs returns the attribute key of every item in the list field of n
+func listAttrs(t *testing.T, n ast.Node, field, key string) []string {
+	t.Helper()
+
+	for _, child := range n.Children() {
+		if child.Type() != renderers.ListNode {
+			continue
+		}
+		if a, ok := child.GetAttribute(renderers.FieldAttr); !ok || a.AsStringOr("") != field {
+			continue
+		}
+
+		values := make([]string, 0)
+		for _, item := range child.Children() {
+			values = append(values, attr(t, item, key))
+		}
+		return values
+	}
+
+	t.Fatalf("node has no %q list", field)
+	return nil
+}
+
+func TestSecretHandlersWithTarget(t *testing.T) {
+	newTestWorkspace(t)
+
+	_, err := SetSecretHandler("", "global@v1", "DB_URL", "postgres://db", false)
+	errs.AssertNoError(t, err)
+
+	_, err = SetSecretHandler("", "global@v1", "DB_URL", "postgres://other", false)
+	assertCauseCode(t, err, error_codes.SecretExistsErrCode)
+
+	_, err = SetSecretHandler("", "global@v1", "DB_URL", "postgres://other", true)
+	errs.AssertNoError(t, err)
+
+	n, err := GetSecretHandler("", "global@v1", "DB_URL")
+	errs.AssertNoError(t, err)
+	if got := attr(t, n, "value"); got != "postgres://other" {
+		t.Errorf("get value = %q, want %q", got, "postgres://other")
+	}
+	if got := attr(t, n, "version"); got != "2" {
+		t.Errorf("get version = %q, want %q", got, "2")
+	}
+
+	n, err = ListSecretsHandler("", "global@v1")
+	errs.AssertNoError(t, err)
+	if got := listAttrs(t, n, "secrets", "key"); !reflect.DeepEqual(got, []string{"DB_URL"}) {
+		t.Errorf("ls keys = %v, want [DB_URL]", got)
+	}
+
+	_, err = RemoveSecretHandler("", "global@v1", "DB_URL")
+	errs.AssertNoError(t, err)
+
+	_, err = GetSecretHandler("", "global@v1", "DB_URL")
+	assertCauseCode(t, err, error_codes.SecretNotFoundErrCode)
+
+	n, err = ListSecretsHandler("", "global@v1")
+	errs.AssertNoError(t, err)
+	if got := listAttrs(t, n, "secrets", "key"); len(got) != 0 {
+		t.Errorf("ls keys after rm = %v, want none", got)
+	}
+}
+
+func TestSecretHandlersWithCurrentProject(t *testing.T) {
+	newTestWorkspace(t)
+
+	_, err := SetSecretHandler("", "", "API_KEY", "s3cret", false)
+	errs.AssertNoError(t, err)
+
+	n, err := GetSecretHandler("", "", "API_KEY")
+	errs.AssertNoError(t, err)
+	if got := attr(t, n, "value"); got != "s3cret" {
+		t.Errorf("get value = %q, want %q", got, "s3cret")
+	}
+	if got := attr(t, n, "vault"); got != "v1" {
+		t.Errorf("get vault = %q, want %q", got, "v1")
+	}
+
+	n, err = ListSecretsHandler("", "")
+	errs.AssertNoError(t, err)
+	if got := listAttrs(t, n, "secrets", "name"); !reflect.DeepEqual(got, []string{"API_KEY"}) {
+		t.Errorf("ls names = %v, want [API_KEY]", got)
+	}
+
+	_, err = RemoveSecretHandler("", "", "API_KEY")
+	errs.AssertNoError(t, err)
+
+	_, err = GetSecretHandler("", "", "API_KEY")
+	assertCauseCode(t, err, error_codes.SecretNotFoundErrCode)
+}
+
+func TestSecretHandlersUnmappedKey(t *testing.T) {
+	newTestWorkspace(t)
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{name: "set", run: func() error {
+			_, err := SetSecretHandler("", "", "NEW_KEY", "value", false)
+			return err
+		}},
+		{name: "get", run: func() error {
+			_, err := GetSecretHandler("", "", "NEW_KEY")
+			return err
+		}},
+		{name: "rm", run: func() error {
+			_, err := RemoveSecretHandler("", "", "NEW_KEY")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			assertCauseCode(t, err, error_codes.SecretNotFoundErrCode)
+			errs.AssertErrorContains(t, err, "--target collection@vault")
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"github.com/tomdoesdev/knox/internal/snapshot"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+)
+
+// withSnapshot runs the destructive operation fn after capture has added the
+// files it changes to a new snapshot, so that knox undo can put them back. The
+// snapshot is kept only if fn succeeds and its id is set on the current node.
+// When snapshots are disabled fn simply runs.
+func withSnapshot(ws *workspace.Workspace, b ast.Builder, operation string, capture func(*snapshot.Snapshot) error, fn func() error) error {
+	store, err := ws.Snapshots()
+	if err != nil {
+		return err
+	}
+	if !store.Enabled() {
+		return fn()
+	}
+
+	sn, err := store.Begin(operation)
+	if err != nil {
+		return err
+	}
+
+	if err := capture(sn); err != nil {
+		store.Discard(sn)
+		return err
+	}
+
+	if err := fn(); err != nil {
+		store.Discard(sn)
+		return err
+	}
+
+	if err := store.Commit(sn); err != nil {
+		return err
+	}
+
+	b.Attr("snapshot", sn.ID)
+	return nil
+}
+
+// captureVault adds the open vault v, linked under alias, to a snapshot
+func captureVault(ws *workspace.Workspace, alias string, v *vault.Vault) func(*snapshot.Snapshot) error {
+	return func(sn *snapshot.Snapshot) error {
+		linked, err := ws.GetLinkedVault(alias)
+		if err != nil {
+			return err
+		}
+
+		return sn.AddVault(alias, linked.Path, v)
+	}
+}
+
+// captureProjects adds the named project files to a snapshot
+func captureProjects(ws *workspace.Workspace, names ...string) func(*snapshot.Snapshot) error {
+	return func(sn *snapshot.Snapshot) error {
+		for _, name := range names {
+			if err := sn.AddProject(name, ws.ProjectPath(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
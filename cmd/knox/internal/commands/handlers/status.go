@@ -1,88 +1,175 @@
 package handlers
 
 import (
-	"log/slog"
-	"os"
+	"fmt"
 
 	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/vault"
 	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/fs"
 )
 
-func StatusHandler() error {
-	return common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
-		_, err := os.Getwd()
+// Linked vault states reported by knox status
+const (
+	vaultStateOK      = "ok"
+	vaultStateMissing = "missing"
+	vaultStateInvalid = "invalid"
+)
+
+func StatusHandler() (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		currentProject, _ := ws.CurrentProject()
+
+		b.Attr("workspace", ws.Dir()).Attr("current_project", currentProject).
+			Node(renderers.MessageNode).Content("Workspace Directory:").
+			Node(renderers.MessageNode).Content(ws.Dir()).Up().
+			Up()
+
+		shownProject := currentProject
+		if shownProject == "" {
+			shownProject = "none"
+		}
+		b.Node(renderers.MessageNode).Content("Current Project:").
+			Node(renderers.MessageNode).Content(shownProject).Up().
+			Up()
+
+		if err := addStatusProjects(b, ws, currentProject); err != nil {
+			return err
+		}
+
+		linked, err := addStatusVaults(b, ws)
 		if err != nil {
 			return err
 		}
 
-		//// Create the AST tree using domain-specific constructors
-		//root := output.NewRoot()
-		//
-		//// Workspace Directory section
-		//workspaceDirHeading := output.NewHeading("Workspace Directory")
-		//workspaceDirHeading.AddChild(output.Text(cwd))
-		//root.AddChild(workspaceDirHeading)
-		//
-		//// Projects section
-		//projects, err := ws.ListProjects()
-		//if err != nil {
-		//	return err
-		//}
-		//
-		//projectsHeading := output.NewHeading("Projects")
-		//if len(projects) == 0 {
-		//	projectsHeading.AddChild(output.Text("none"))
-		//} else {
-		//	projectsList := output.NewList()
-		//
-		//	// Get current active project
-		//	currentProject, _ := ws.CurrentProject()
-		//
-		//	for _, project := range projects {
-		//		listItem := output.NewListItem(project)
-		//		if project == currentProject {
-		//			// Use attributes to mark active project
-		//			listItem.SetAttribute("active", true)
-		//			listItem.SetContent(ast.StringValue(project + "   [active]"))
-		//		}
-		//		projectsList.AddChild(listItem)
-		//	}
-		//	projectsHeading.AddChild(projectsList)
-		//}
-		//root.AddChild(projectsHeading)
-		//
-		//// Linked Vaults section
-		//vaults, err := ws.GetLinkedVaults()
-		//if err != nil {
-		//	return err
-		//}
-		//
-		//vaultsHeading := output.NewHeading("Linked Vaults")
-		//if len(vaults) == 0 {
-		//	vaultsHeading.AddChild(output.Text("None"))
-		//} else {
-		//	vaultsList := output.NewList()
-		//	for _, vault := range vaults {
-		//		vaultItem := output.NewListItem(vault.Alias + " " + vault.Path)
-		//		// Add metadata attributes for potential future use
-		//		vaultItem.SetAttribute("alias", vault.Alias)
-		//		vaultItem.SetAttribute("path", vault.Path)
-		//		vaultsList.AddChild(vaultItem)
-		//	}
-		//	vaultsHeading.AddChild(vaultsList)
-		//}
-		//root.AddChild(vaultsHeading)
-		//
-		//// Create CLI renderer and render
-		//renderer := cli.NewRenderer()
-		//result, err := renderer.Marshal(root)
-		//if err != nil {
-		//	return fmt.Errorf("failed to render status output: %w", err)
-		//}
-
-		panic("todo")
-		slog.Debug("knox workspace dir", "dir", ws.DataDir())
-
-		return nil
+		return addDanglingReferences(b, ws, linked)
 	})
+
+	return b.Build(), err
+}
+
+func addStatusProjects(b ast.Builder, ws *workspace.Workspace, currentProject string) error {
+	projects, err := ws.ListProjects()
+	if err != nil {
+		return err
+	}
+
+	b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "projects").
+		Content(fmt.Sprintf("Projects (%d):", len(projects)))
+	if len(projects) == 0 {
+		b.Node(renderers.MessageNode).Content("none").Up()
+	}
+
+	for _, name := range projects {
+		project, err := ws.LoadProject(name)
+		if err != nil {
+			return err
+		}
+
+		count := len(project.ListSecrets())
+		line := fmt.Sprintf("%s (%d secrets)", name, count)
+		if name == currentProject {
+			line += "   [active]"
+		}
+
+		b.Node(renderers.ItemNode).
+			Attr("name", name).
+			Attr("secret_count", count).
+			Attr("current", name == currentProject).
+			Content(line).
+			Up()
+	}
+
+	b.Up()
+	return nil
+}
+
+// addStatusVaults reports every linked vault and returns the set of linked aliases
+func addStatusVaults(b ast.Builder, ws *workspace.Workspace) (map[string]bool, error) {
+	vaults, err := ws.GetLinkedVaults()
+	if err != nil {
+		return nil, err
+	}
+
+	b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "vaults").
+		Content(fmt.Sprintf("Linked Vaults (%d):", len(vaults)))
+	if len(vaults) == 0 {
+		b.Node(renderers.MessageNode).Content("none").Up()
+	}
+
+	linked := make(map[string]bool, len(vaults))
+	for _, lv := range vaults {
+		linked[lv.Alias] = true
+
+		state, err := checkVaultLink(lv.Path)
+
+		b.Node(renderers.ItemNode).Attr("alias", lv.Alias).Attr("path", lv.Path).Attr("stored_path", lv.StoredPath).Attr("vault_id", lv.VaultID)
+		if err != nil {
+			b.Attr("error", err.Error())
+		}
+
+		b.Attr("reachable", state != vaultStateMissing).
+			Attr("is_vault", state == vaultStateOK).
+			Attr("state", state).
+			Content(fmt.Sprintf("%s %s (%s)", lv.Alias, lv.Path, state)).
+			Up()
+	}
+
+	b.Up()
+	return linked, nil
+}
+
+// checkVaultLink reports whether the file behind a vault link exists and is a vault
+func checkVaultLink(path string) (string, error) {
+	if !fs.IsFile(path) {
+		return vaultStateMissing, nil
+	}
+
+	isVault, err := vault.IsVault(path)
+	if err != nil {
+		return vaultStateInvalid, err
+	}
+	if !isVault {
+		return vaultStateInvalid, nil
+	}
+
+	return vaultStateOK, nil
+}
+
+// addDanglingReferences reports project secrets that point at vault aliases
+// that are not linked to the workspace
+func addDanglingReferences(b ast.Builder, ws *workspace.Workspace, linked map[string]bool) error {
+	refs, err := ws.ProjectReferences()
+	if err != nil {
+		return err
+	}
+
+	var dangling []workspace.ProjectReference
+	for _, r := range refs {
+		if !linked[r.Reference.Vault] {
+			dangling = append(dangling, r)
+		}
+	}
+
+	b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "dangling_references")
+	if len(dangling) > 0 {
+		b.Content(fmt.Sprintf("Dangling References (%d):", len(dangling)))
+	}
+
+	for _, r := range dangling {
+		b.Node(renderers.ItemNode).
+			Attr("project", r.Project).
+			Attr("name", r.Name).
+			Attr("reference", r.Reference.String()).
+			Attr("vault", r.Reference.Vault).
+			Content(fmt.Sprintf("%s: %s -> %s (vault '%s' is not linked)", r.Project, r.Name, r.Reference, r.Reference.Vault)).
+			Up()
+	}
+
+	b.Up()
+	return nil
 }
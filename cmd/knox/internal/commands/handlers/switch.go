@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+)
+
+func SwitchHandler(name string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		// Loading the project checks that it exists and is valid
+		if _, err := ws.LoadProject(name); err != nil {
+			return err
+		}
+
+		previous, _ := ws.CurrentProject()
+		if err := ws.SetCurrentProject(name); err != nil {
+			return err
+		}
+
+		b.Attr("project", name).Attr("previous", previous).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Switched to project '%s'", name)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+)
+
+// Actions taken by knox tidy
+const (
+	tidyPruned   = "pruned"
+	tidyRepaired = "repaired"
+	tidySkipped  = "skipped"
+	tidyReported = "reported"
+	// tidyRelocated marks a link whose vault was found again by its id
+	tidyRelocated = "relocated"
+)
+
+// TidyOptions controls how knox tidy deals with the problems it finds
+type TidyOptions struct {
+	// DryRun only reports problems
+	DryRun bool
+	// Yes prunes every problem without prompting
+	Yes bool
+}
+
+// tidier applies one of prune, repair or skip to each problem. Without a
+// terminal, and without --yes, problems are only reported.
+type tidier struct {
+	ws          *workspace.Workspace
+	opts        TidyOptions
+	interactive bool
+	counts      map[string]int
+}
+
+func TidyHandler(opts TidyOptions) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		t := &tidier{
+			ws:          ws,
+			opts:        opts,
+			interactive: !opts.DryRun && !opts.Yes && common.IsTerminal(os.Stdin),
+			counts:      make(map[string]int),
+		}
+
+		// Links go first so references to pruned links are tidied in the same run
+		if err := t.tidyLinks(b); err != nil {
+			return err
+		}
+		if err := t.tidyReferences(b); err != nil {
+			return err
+		}
+
+		t.summarize(b)
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+func (t *tidier) tidyLinks(b ast.Builder) error {
+	vaults, err := t.ws.GetLinkedVaults()
+	if err != nil {
+		return err
+	}
+
+	b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "links")
+	for _, lv := range vaults {
+		state, _ := checkVaultLink(lv.Path)
+		if state == vaultStateOK {
+			continue
+		}
+
+		action, err := t.tidyLink(lv, state)
+		if err != nil {
+			return err
+		}
+		t.counts[action]++
+
+		b.Node(renderers.ItemNode).
+			Attr("alias", lv.Alias).
+			Attr("path", lv.Path).
+			Attr("state", state).
+			Attr("action", action).
+			Content(fmt.Sprintf("vault '%s' at %s is %s: %s", lv.Alias, lv.Path, state, action)).
+			Up()
+	}
+	b.Up()
+
+	return nil
+}
+
+func (t *tidier) tidyLink(lv workspace.LinkedVault, state string) (string, error) {
+	if state == vaultStateMissing && !t.opts.DryRun {
+		path, err := t.ws.RelocateVault(lv.Alias)
+		if err != nil {
+			return "", err
+		}
+		if path != "" {
+			return tidyRelocated, nil
+		}
+	}
+
+	choice := t.choose(fmt.Sprintf("Vault '%s' at %s is %s.", lv.Alias, lv.Path, state))
+
+	switch choice {
+	case tidyPruned:
+		return tidyPruned, t.ws.UnlinkVault(lv.Alias)
+	case tidyRepaired:
+		path, err := common.Prompt(fmt.Sprintf("New path for vault '%s': ", lv.Alias))
+		if err != nil {
+			return "", err
+		}
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			common.Warn("invalid path '%s': %v", path, err)
+			return tidySkipped, nil
+		}
+		if isVault, err := vault.IsVault(absPath); err != nil || !isVault {
+			common.Warn("no valid vault found at %s", absPath)
+			return tidySkipped, nil
+		}
+
+		if err := t.ws.SetLinkedVaultPath(lv.Alias, absPath); err != nil {
+			common.Warn("failed to relink vault '%s': %v", lv.Alias, err)
+			return tidySkipped, nil
+		}
+		return tidyRepaired, nil
+	}
+
+	return choice, nil
+}
+
+func (t *tidier) tidyReferences(b ast.Builder) error {
+	vaults, err := t.ws.GetLinkedVaults()
+	if err != nil {
+		return err
+	}
+	linked := make(map[string]bool, len(vaults))
+	for _, lv := range vaults {
+		linked[lv.Alias] = true
+	}
+
+	refs, err := t.ws.ProjectReferences()
+	if err != nil {
+		return err
+	}
+
+	var dangling []workspace.ProjectReference
+	var projects []string
+	for _, r := range refs {
+		if linked[r.Reference.Vault] {
+			continue
+		}
+		if len(projects) == 0 || projects[len(projects)-1] != r.Project {
+			projects = append(projects, r.Project)
+		}
+		dangling = append(dangling, r)
+	}
+
+	addReferences := func() error {
+		b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "references")
+		for _, r := range dangling {
+			action, err := t.tidyReference(r, linked)
+			if err != nil {
+				return err
+			}
+			t.counts[action]++
+
+			b.Node(renderers.ItemNode).
+				Attr("project", r.Project).
+				Attr("name", r.Name).
+				Attr("reference", r.Reference.String()).
+				Attr("action", action).
+				Content(fmt.Sprintf("%s: %s -> %s references unlinked vault '%s': %s", r.Project, r.Name, r.Reference, r.Reference.Vault, action)).
+				Up()
+		}
+		b.Up()
+		return nil
+	}
+
+	if len(dangling) == 0 || !t.changesProjects() {
+		return addReferences()
+	}
+
+	return withSnapshot(t.ws, b, "tidy", captureProjects(t.ws, projects...), addReferences)
+}
+
+// changesProjects reports whether tidying references may rewrite project files
+func (t *tidier) changesProjects() bool {
+	return !t.opts.DryRun && (t.opts.Yes || t.interactive)
+}
+
+func (t *tidier) tidyReference(r workspace.ProjectReference, linked map[string]bool) (string, error) {
+	choice := t.choose(fmt.Sprintf("Project '%s' secret '%s' references unlinked vault '%s'.", r.Project, r.Name, r.Reference.Vault))
+	if choice != tidyPruned && choice != tidyRepaired {
+		return choice, nil
+	}
+
+	project, err := t.ws.LoadProject(r.Project)
+	if err != nil {
+		return "", err
+	}
+
+	if choice == tidyPruned {
+		project.RemoveSecret(r.Name)
+		return tidyPruned, t.ws.RepairProject(project)
+	}
+
+	alias, err := common.Prompt("Vault alias to use instead: ")
+	if err != nil {
+		return "", err
+	}
+	if !linked[alias] {
+		common.Warn("vault alias '%s' is not linked to workspace", alias)
+		return tidySkipped, nil
+	}
+
+	repaired := *r.Reference
+	repaired.Vault = alias
+	project.AddSecret(r.Name, repaired.String())
+
+	return tidyRepaired, t.ws.RepairProject(project)
+}
+
+// choose decides what to do with a problem, prompting when running interactively
+func (t *tidier) choose(problem string) string {
+	switch {
+	case t.opts.DryRun:
+		return tidyReported
+	case t.opts.Yes:
+		return tidyPruned
+	case !t.interactive:
+		return tidyReported
+	}
+
+	for {
+		answer, err := common.Prompt(problem + " [p]rune, [r]epair, [s]kip? ")
+		if err != nil {
+			return tidySkipped
+		}
+
+		switch answer {
+		case "p", "prune":
+			return tidyPruned
+		case "r", "repair":
+			return tidyRepaired
+		case "s", "skip", "":
+			return tidySkipped
+		}
+	}
+}
+
+func (t *tidier) summarize(b ast.Builder) {
+	repaired := t.counts[tidyRepaired] + t.counts[tidyRelocated]
+	b.Attr("pruned", t.counts[tidyPruned]).
+		Attr("repaired", repaired).
+		Attr("skipped", t.counts[tidySkipped]).
+		Attr("reported", t.counts[tidyReported])
+
+	total := t.counts[tidyPruned] + repaired + t.counts[tidySkipped] + t.counts[tidyReported]
+	if total == 0 {
+		b.Node(renderers.MessageNode).Content("Nothing to tidy").Up()
+		return
+	}
+
+	if t.counts[tidyReported] > 0 && !t.opts.DryRun {
+		b.Node(renderers.MessageNode).Content("Run 'knox tidy --yes' to prune, or run it in a terminal to choose").Up()
+	}
+
+	b.Node(renderers.MessageNode).Content(fmt.Sprintf("Pruned %d, repaired %d, skipped %d, reported %d",
+		t.counts[tidyPruned], repaired, t.counts[tidySkipped], t.counts[tidyReported])).Up()
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/snapshot"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// UndoHandler restores the files captured by the most recent snapshot and
+// removes it, so running it again undoes the operation before that. Files
+// changed since the snapshot was taken are only replaced when force is set.
+func UndoHandler(force bool) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		store, err := ws.Snapshots()
+		if err != nil {
+			return err
+		}
+
+		sn, err := store.Latest()
+		if err != nil {
+			return err
+		}
+
+		changed, err := store.Changed(sn)
+		if err != nil {
+			return err
+		}
+		if len(changed) > 0 {
+			names := make([]string, 0, len(changed))
+			for _, f := range changed {
+				names = append(names, fmt.Sprintf("%s '%s'", f.Kind, f.Name))
+			}
+			if !force {
+				return errs.New(error_codes.SnapshotChangedErrCode, "files were changed after the snapshot, undoing would discard those changes, use --force to undo anyway").
+					WithContext("snapshot", sn.ID).
+					WithContext("changed", strings.Join(names, ", "))
+			}
+			common.Warn("discarding changes made since the snapshot to %s", strings.Join(names, ", "))
+		}
+
+		if err := store.Restore(sn); err != nil {
+			return err
+		}
+
+		b.Attr("snapshot", sn.ID).
+			Attr("operation", sn.Operation).
+			Attr("created_at", sn.CreatedAt.Format(time.RFC3339))
+		addSnapshotFiles(b, sn)
+		b.Node(renderers.MessageNode).
+			Content(fmt.Sprintf("Undid '%s' from %s", sn.Operation, sn.CreatedAt.Local().Format(time.DateTime))).
+			Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+func UndoListHandler() (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		store, err := ws.Snapshots()
+		if err != nil {
+			return err
+		}
+
+		snapshots, err := store.List()
+		if err != nil {
+			return err
+		}
+
+		if !store.Enabled() {
+			common.Warn("snapshots are disabled, see 'knox config %s'", workspace.SnapshotRetentionSetting)
+		}
+
+		b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "snapshots")
+		if len(snapshots) > 0 {
+			b.Content(fmt.Sprintf("Snapshots, newest first (%d):", len(snapshots)))
+		}
+		for _, sn := range snapshots {
+			b.Node(renderers.ItemNode).
+				Attr("id", sn.ID).
+				Attr("operation", sn.Operation).
+				Attr("created_at", sn.CreatedAt.Format(time.RFC3339)).
+				Content(fmt.Sprintf("%s  %s", sn.CreatedAt.Local().Format(time.DateTime), sn.Operation))
+			addSnapshotFiles(b, &sn)
+			b.Up()
+		}
+		b.Up()
+
+		if len(snapshots) == 0 {
+			b.Node(renderers.MessageNode).Content("No snapshots to undo").Up()
+		}
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+// addSnapshotFiles lists the files captured in sn under the current node
+func addSnapshotFiles(b ast.Builder, sn *snapshot.Snapshot) {
+	b.Node(renderers.ListNode).Attr(renderers.FieldAttr, "files")
+	for _, f := range sn.Files {
+		b.Node(renderers.ItemNode).
+			Attr("kind", f.Kind).
+			Attr("name", f.Name).
+			Attr("path", f.Path).
+			Content(fmt.Sprintf("%s '%s' (%s)", f.Kind, f.Name, f.Path)).
+			Up()
+	}
+	b.Up()
+}
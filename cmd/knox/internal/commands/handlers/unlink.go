@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+func UnlinkHandler(alias string, force bool) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		linked, err := ws.GetLinkedVault(alias)
+		if err != nil {
+			return err
+		}
+
+		refs, err := ws.ReferencesTo(alias, "")
+		if err != nil {
+			return err
+		}
+
+		if len(refs) > 0 && !force {
+			return errs.New(error_codes.VaultInUseErrCode, "vault is referenced by projects, use --force to unlink anyway").
+				WithContext("alias", alias).
+				WithContext("references", len(refs)).
+				WithContext("projects", strings.Join(referencingProjects(refs), ", "))
+		}
+
+		if err := ws.UnlinkVault(alias); err != nil {
+			return err
+		}
+
+		for _, r := range refs {
+			common.Warn("project '%s' secret '%s' still references '%s'", r.Project, r.Name, r.Reference)
+		}
+
+		b.Attr("alias", alias).Attr("path", linked.Path).Attr("dangling_references", len(refs)).
+			Node(renderers.MessageNode).Content(fmt.Sprintf("Unlinked vault '%s' (%s)", alias, linked.Path)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+// referencingProjects returns the distinct project names in refs, sorted
+func referencingProjects(refs []workspace.ProjectReference) []string {
+	seen := make(map[string]bool)
+	var projects []string
+	for _, r := range refs {
+		if !seen[r.Project] {
+			seen[r.Project] = true
+			projects = append(projects, r.Project)
+		}
+	}
+	sort.Strings(projects)
+
+	return projects
+}
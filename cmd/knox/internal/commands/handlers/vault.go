@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/renderers"
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/snapshot"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/tomdoesdev/knox/kit/fs"
+)
+
+func VaultEncryptHandler(alias string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		return withVault(ws, alias, func(v *vault.Vault) error {
+			if v.IsEncrypted() {
+				return errs.New(error_codes.VaultEncryptionErrCode, "vault is already encrypted").WithContext("alias", alias)
+			}
+
+			passphrase, err := common.NewPassphrase(fmt.Sprintf("New passphrase for vault '%s': ", alias))
+			if err != nil {
+				return err
+			}
+
+			if err := v.Encrypt(passphrase); err != nil {
+				return err
+			}
+
+			b.Attr("alias", alias).Attr("encrypted", true).
+				Node(renderers.MessageNode).Content(fmt.Sprintf("Encrypted vault '%s'", alias)).Up()
+			return nil
+		})
+	})
+
+	return b.Build(), err
+}
+
+// backupsDirName is the directory next to a vault file that holds its backups
+const backupsDirName = "backups"
+
+// VaultBackupHandler writes a consistent snapshot of the vault linked under
+// alias to dest. An empty dest, or a directory, gets a timestamped file name,
+// placed in the backups directory next to the vault when dest is empty.
+func VaultBackupHandler(alias, dest string) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		return withVault(ws, alias, func(v *vault.Vault) error {
+			linked, err := ws.GetLinkedVault(alias)
+			if err != nil {
+				return err
+			}
+
+			path, err := backupPath(linked, dest, "")
+			if err != nil {
+				return err
+			}
+
+			if err := v.Backup(path); err != nil {
+				return err
+			}
+
+			b.Attr("alias", alias).Attr("path", path).
+				Node(renderers.MessageNode).Content(fmt.Sprintf("Backed up vault '%s' to %s", alias, path)).Up()
+			return nil
+		})
+	})
+
+	return b.Build(), err
+}
+
+// VaultRestoreHandler replaces the vault linked under alias with the snapshot
+// at snapshotPath. The snapshot must be a backup of the same vault unless
+// force is set. The current vault is captured in an undo snapshot first, so
+// 'knox undo' puts it back. With snapshots disabled it is backed up next to
+// the vault instead.
+func VaultRestoreHandler(alias, snapshotPath string, force bool) (ast.Node, error) {
+	b := ast.NewBuilder(renderers.ResultNode)
+
+	err := common.WithLocalWorkspace(func(ws *workspace.Workspace) error {
+		linked, err := ws.GetLinkedVault(alias)
+		if err != nil {
+			return err
+		}
+
+		snapshotID, err := vault.ReadID(snapshotPath)
+		if err != nil {
+			return errs.Wrap(err, error_codes.VaultIntegrityErrCode, "snapshot is not a vault").WithPath(snapshotPath)
+		}
+		if linked.VaultID != "" && snapshotID != linked.VaultID {
+			if !force {
+				return errs.New(error_codes.VaultIntegrityErrCode, "snapshot is a backup of a different vault, use --force to restore anyway").
+					WithContext("alias", alias).
+					WithContext("linked_id", linked.VaultID).
+					WithContext("snapshot_id", snapshotID)
+			}
+			common.Warn("restoring a snapshot of a different vault (%s) into '%s'", snapshotID, alias)
+		}
+
+		b.Attr("alias", alias).Attr("path", linked.Path).Attr("snapshot", snapshotPath)
+
+		restore := func() error {
+			return vault.Restore(snapshotPath, linked.Path)
+		}
+		if fs.IsFile(linked.Path) {
+			store, err := ws.Snapshots()
+			if err != nil {
+				return err
+			}
+
+			if store.Enabled() {
+				// The vault is captured while open, then closed again before it is replaced
+				capture := func(sn *snapshot.Snapshot) error {
+					return withVault(ws, alias, func(v *vault.Vault) error {
+						return captureVault(ws, alias, v)(sn)
+					})
+				}
+				err = withSnapshot(ws, b, "vault restore "+alias, capture, restore)
+			} else {
+				err = backupBeforeRestore(ws, b, linked, restore)
+			}
+			if err != nil {
+				return err
+			}
+		} else if err := restore(); err != nil {
+			return err
+		}
+
+		// Record the restored vault's id on the link
+		if snapshotID != linked.VaultID {
+			if err := ws.SetLinkedVaultPath(alias, linked.StoredPath); err != nil {
+				return err
+			}
+		}
+
+		b.Node(renderers.MessageNode).Content(fmt.Sprintf("Restored vault '%s' from %s", alias, snapshotPath)).Up()
+		return nil
+	})
+
+	return b.Build(), err
+}
+
+// backupBeforeRestore backs up the vault linked as linked into its backups
+// directory and then runs restore. It stands in for the undo snapshot when
+// snapshots are disabled.
+func backupBeforeRestore(ws *workspace.Workspace, b ast.Builder, linked *workspace.LinkedVault, restore func() error) error {
+	previous, err := backupPath(linked, "", "pre-restore")
+	if err != nil {
+		return err
+	}
+
+	err = withVault(ws, linked.Alias, func(v *vault.Vault) error {
+		return v.Backup(previous)
+	})
+	if err != nil {
+		return errs.Wrap(err, error_codes.VaultCreationErrCode, "failed to back up vault before restoring").WithContext("alias", linked.Alias)
+	}
+
+	b.Attr("previous", previous).
+		Node(renderers.MessageNode).Content(fmt.Sprintf("Snapshots are disabled, backed up current vault to %s", previous)).Up()
+
+	return restore()
+}
+
+// backupPath decides where a backup of linked goes. An empty dest means the
+// backups directory next to the vault, which is created if needed. label, when
+// set, is added to generated file names.
+func backupPath(linked *workspace.LinkedVault, dest, label string) (string, error) {
+	name := linked.Alias
+	if label != "" {
+		name += "-" + label
+	}
+	name += "-" + time.Now().Format("20060102-150405.000000")
+
+	switch {
+	case dest == "":
+		dir := filepath.Join(filepath.Dir(linked.Path), backupsDirName)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", errs.Wrap(err, error_codes.FilePermissionErrCode, "failed to create backups directory").WithPath(dir)
+		}
+		return uniqueBackupPath(dir, name), nil
+	case fs.IsDir(dest):
+		return uniqueBackupPath(dest, name), nil
+	default:
+		return dest, nil
+	}
+}
+
+// uniqueBackupPath returns a path in dir for a backup called name that no
+// file is using yet, adding a counter to the name when needed
+func uniqueBackupPath(dir, name string) string {
+	path := filepath.Join(dir, name+".db")
+	for i := 1; fs.IsExist(path); i++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.db", name, i))
+	}
+	return path
+}
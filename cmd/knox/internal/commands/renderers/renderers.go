@@ -0,0 +1,85 @@
+// Package renderers turns the result trees returned by command handlers into
+// text, JSON or YAML output.
+//
+// A result tree is an ast.Node of type ResultNode. Its attributes are the
+// top-level fields of structured output. Children are either:
+//
+//   - MessageNode: a line of human readable text. Messages only appear in
+//     text output.
+//   - ListNode: a list stored under the field named by its FieldAttr
+//     attribute. Its content, if any, is printed as a heading in text output.
+//     Each ItemNode child becomes one element, made of the item's attributes
+//     and any lists nested inside it.
+//
+// Text output prints the content of every node in tree order, indenting the
+// children of a node that has content.
+package renderers
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/ast"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+const (
+	ResultNode  = "result"
+	MessageNode = "message"
+	ListNode    = "list"
+	ItemNode    = "item"
+
+	// FieldAttr names the structured output field a ListNode is stored under
+	FieldAttr = "field"
+)
+
+// DefaultFormat is used when no output format is selected
+const DefaultFormat = "text"
+
+// Renderer writes a result tree to w
+type Renderer interface {
+	Render(w io.Writer, root ast.Node) error
+}
+
+// RendererFunc adapts a function to the Renderer interface
+type RendererFunc func(w io.Writer, root ast.Node) error
+
+func (f RendererFunc) Render(w io.Writer, root ast.Node) error {
+	return f(w, root)
+}
+
+var registry = map[string]Renderer{
+	"text": RendererFunc(renderText),
+	"json": RendererFunc(renderJSON),
+	"yaml": RendererFunc(renderYAML),
+}
+
+// Register adds a renderer for format, replacing any existing one
+func Register(format string, r Renderer) {
+	registry[format] = r
+}
+
+// Lookup returns the renderer registered for format
+func Lookup(format string) (Renderer, error) {
+	r, ok := registry[format]
+	if !ok {
+		return nil, errs.New(error_codes.ValidationErrCode, "unknown output format").
+			WithContext("format", format).
+			WithContext("supported", strings.Join(Formats(), ", "))
+	}
+
+	return r, nil
+}
+
+// Formats returns the names of all registered formats, sorted
+func Formats() []string {
+	formats := make([]string, 0, len(registry))
+	for format := range registry {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
@@ -0,0 +1,61 @@
+package renderers
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/tomdoesdev/knox/kit/ast"
+	"gopkg.in/yaml.v3"
+)
+
+func renderJSON(w io.Writer, root ast.Node) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(objectData(root))
+}
+
+func renderYAML(w io.Writer, root ast.Node) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(objectData(root)); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// objectData collects the attributes of node and the lists below it into the
+// value encoded for structured output
+func objectData(node ast.Node) map[string]any {
+	data := make(map[string]any)
+	if node == nil {
+		return data
+	}
+
+	for key, attr := range node.Attributes() {
+		data[key] = attr.Value()
+	}
+
+	for _, child := range node.Children() {
+		if child.Type() != ListNode {
+			continue
+		}
+
+		attr, ok := child.GetAttribute(FieldAttr)
+		if !ok {
+			continue
+		}
+
+		items := make([]map[string]any, 0, len(child.Children()))
+		for _, item := range child.Children() {
+			if item.Type() == ItemNode {
+				items = append(items, objectData(item))
+			}
+		}
+		data[attr.AsStringOr(FieldAttr)] = items
+	}
+
+	return data
+}
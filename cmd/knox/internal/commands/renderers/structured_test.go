@@ -0,0 +1,122 @@
+package renderers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tomdoesdev/knox/kit/ast"
+)
+
+// testTree builds a result with scalar fields, a message, a list of items
+// with a nested list, and an empty list
+func testTree() ast.Node {
+	b := ast.NewBuilder(ResultNode)
+	b.Attr("project", "default").Attr("count", 2).Attr("healthy", true)
+	b.Node(MessageNode).Content("Found 2 secrets").Up()
+
+	b.Node(ListNode).Attr(FieldAttr, "secrets").Content("Secrets (2):")
+	b.Node(ItemNode).Attr("name", "API_KEY").Attr("reference", "API_KEY@v1/global").Content("API_KEY").
+		Node(ListNode).Attr(FieldAttr, "versions").
+		Node(ItemNode).Attr("version", 1).Attr("current", false).Up().
+		Node(ItemNode).Attr("version", 2).Attr("current", true).Up().
+		Up().
+		Up()
+	b.Node(ItemNode).Attr("name", "DB_URL").Attr("reference", "DB_URL@v1/global").Content("DB_URL").Up()
+	b.Up()
+
+	b.Node(ListNode).Attr(FieldAttr, "warnings").Up()
+
+	return b.Build()
+}
+
+func TestRenderStructured(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "json",
+			want: `{
+  "count": 2,
+  "healthy": true,
+  "project": "default",
+  "secrets": [
+    {
+      "name": "API_KEY",
+      "reference": "API_KEY@v1/global",
+      "versions": [
+        {
+          "current": false,
+          "version": 1
+        },
+        {
+          "current": true,
+          "version": 2
+        }
+      ]
+    },
+    {
+      "name": "DB_URL",
+      "reference": "DB_URL@v1/global"
+    }
+  ],
+  "warnings": []
+}
+`,
+		},
+		{
+			format: "yaml",
+			want: `count: 2
+healthy: true
+project: default
+secrets:
+  - name: API_KEY
+    reference: API_KEY@v1/global
+    versions:
+      - current: false
+        version: 1
+      - current: true
+        version: 2
+  - name: DB_URL
+    reference: DB_URL@v1/global
+warnings: []
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			r, err := Lookup(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			if err := r.Render(&out, testTree()); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("Render() =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderStructured_NilRoot(t *testing.T) {
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			r, err := Lookup(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			if err := r.Render(&out, nil); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got := out.String(); got != "{}\n" {
+				t.Errorf("Render(nil) = %q, want %q", got, "{}\n")
+			}
+		})
+	}
+}
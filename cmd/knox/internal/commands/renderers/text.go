@@ -0,0 +1,39 @@
+package renderers
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/tomdoesdev/knox/kit/ast"
+)
+
+func renderText(w io.Writer, root ast.Node) error {
+	if root == nil {
+		return nil
+	}
+
+	bw := bufio.NewWriter(w)
+	writeText(bw, root, "")
+
+	return bw.Flush()
+}
+
+func writeText(w *bufio.Writer, node ast.Node, indent string) {
+	childIndent := indent
+
+	if content := node.Content().String(); content != "" {
+		for _, line := range strings.Split(content, "\n") {
+			if line != "" {
+				_, _ = w.WriteString(indent)
+			}
+			_, _ = w.WriteString(line)
+			_ = w.WriteByte('\n')
+		}
+		childIndent += "  "
+	}
+
+	for _, child := range node.Children() {
+		writeText(w, child, childIndent)
+	}
+}
@@ -6,22 +6,50 @@ import (
 	"os"
 
 	"github.com/tomdoesdev/knox/cmd/knox/internal/commands"
+	"github.com/tomdoesdev/knox/cmd/knox/internal/commands/common"
+	"github.com/tomdoesdev/knox/internal/workspace"
 	"github.com/tomdoesdev/knox/kit/log"
 	"github.com/urfave/cli/v3"
 )
 
 func main() {
 	log.NewSlog(log.Text)
+	workspace.Warn = common.Warn
 
 	app := &cli.Command{
 		Name:  "knox",
 		Usage: "local development secrets manager",
+		Flags: []cli.Flag{
+			common.NewOutputFlag(),
+			common.NewProjectFlag(),
+		},
 		Commands: []*cli.Command{
 			commands.NewInitCommand(),
 			commands.NewNewCommand(),
 			commands.NewProjectCommand(),
+			commands.NewSwitchCommand(),
 			commands.NewLinkCommand(),
+			commands.NewUnlinkCommand(),
+			commands.NewRelinkCommand(),
+			commands.NewTidyCommand(),
 			commands.NewStatusCommand(),
+			commands.NewDoctorCommand(),
+			commands.NewFixPermsCommand(),
+			commands.NewSetCommand(),
+			commands.NewGetCommand(),
+			commands.NewRmCommand(),
+			commands.NewLsCommand(),
+			commands.NewHistoryCommand(),
+			commands.NewRollbackCommand(),
+			commands.NewUndoCommand(),
+			commands.NewVaultCommand(),
+			commands.NewCollectionCommand(),
+			commands.NewRunCommand(),
+			commands.NewRenderCommand(),
+			commands.NewMigrateCommand(),
+			commands.NewConfigCommand(),
+			commands.NewImportCommand(),
+			commands.NewExportCommand(),
 		},
 	}
 
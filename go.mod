@@ -5,4 +5,8 @@ go 1.24.5
 require (
 	github.com/mattn/go-sqlite3 v1.14.28
 	github.com/urfave/cli/v3 v3.3.8
+	golang.org/x/term v0.40.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/sys v0.41.0 // indirect
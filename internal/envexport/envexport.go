@@ -0,0 +1,148 @@
+package envexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/dotenv"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats resolved environments can be exported in
+const (
+	DotenvFormat    = "dotenv"
+	JSONFormat      = "json"
+	YAMLFormat      = "yaml"
+	ShellFormat     = "shell"
+	DockerEnvFormat = "docker-env"
+)
+
+// encoders maps each format to the function producing it. Every encoder
+// orders variables by name.
+var encoders = map[string]func(vars map[string]string) ([]byte, error){
+	DotenvFormat:    encodeDotenv,
+	JSONFormat:      encodeJSON,
+	YAMLFormat:      encodeYAML,
+	ShellFormat:     encodeShell,
+	DockerEnvFormat: encodeDockerEnv,
+}
+
+var shellName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Formats returns the supported export formats, sorted
+func Formats() []string {
+	formats := make([]string, 0, len(encoders))
+	for format := range encoders {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// ValidateFormat rejects formats Encode does not support
+func ValidateFormat(format string) error {
+	if _, ok := encoders[format]; !ok {
+		return errs.New(error_codes.ValidationErrCode, "unknown export format").
+			WithContext("format", format).
+			WithContext("formats", strings.Join(Formats(), ", "))
+	}
+	return nil
+}
+
+// Encode formats vars in the given format
+func Encode(format string, vars map[string]string) ([]byte, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+
+	return encoders[format](vars)
+}
+
+func encodeDotenv(vars map[string]string) ([]byte, error) {
+	return dotenv.Marshal(vars), nil
+}
+
+func encodeJSON(vars map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(vars); err != nil {
+		return nil, errs.Wrap(err, error_codes.ValidationErrCode, "failed to encode json")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeYAML(vars map[string]string) ([]byte, error) {
+	if len(vars) == 0 {
+		return []byte("{}\n"), nil
+	}
+
+	data, err := yaml.Marshal(vars)
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.ValidationErrCode, "failed to encode yaml")
+	}
+
+	return data, nil
+}
+
+// encodeShell writes export statements for POSIX shells. Values are single
+// quoted, which leaves everything but the single quote itself uninterpreted.
+func encodeShell(vars map[string]string) ([]byte, error) {
+	var b strings.Builder
+	for _, name := range sortedNames(vars) {
+		if !shellName.MatchString(name) {
+			return nil, errs.New(error_codes.ValidationErrCode, "name is not a valid shell variable name").WithContext("name", name)
+		}
+
+		b.WriteString("export ")
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString("'" + strings.ReplaceAll(vars[name], "'", `'\''`) + "'")
+		b.WriteByte('\n')
+	}
+
+	return []byte(b.String()), nil
+}
+
+// encodeDockerEnv writes a file for docker's --env-file, which takes every
+// character after the first '=' literally and has no way to quote or escape
+// newlines
+func encodeDockerEnv(vars map[string]string) ([]byte, error) {
+	var b strings.Builder
+	for _, name := range sortedNames(vars) {
+		value := vars[name]
+		if strings.ContainsAny(name, "= \t\n") {
+			return nil, errs.New(error_codes.ValidationErrCode, "name cannot be used in a docker env file").WithContext("name", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, errs.New(error_codes.ValidationErrCode, "docker env files cannot hold multi-line values, use another format").
+				WithContext("name", name)
+		}
+
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+	}
+
+	return []byte(b.String()), nil
+}
+
+func sortedNames(vars map[string]string) []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
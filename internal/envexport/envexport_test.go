@@ -0,0 +1,126 @@
+package envexport
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/dotenv"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"gopkg.in/yaml.v3"
+)
+
+// tricky holds values that need quoting or escaping in at least one format
+var tricky = map[string]string{
+	"PLAIN":  "value",
+	"QUOTES": `it's "quoted"`,
+	"SHELL":  "$HOME `id` \\n",
+	"HTML":   "<a href=\"x\">&</a>",
+	"EMPTY":  "",
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		format string
+		vars   map[string]string
+		want   string
+	}{
+		{
+			format: DotenvFormat,
+			vars:   map[string]string{"B": "it's \"quoted\" $HOME \\", "A": "line1\nline2\t"},
+			want:   "A=\"line1\\nline2\\t\"\nB=\"it's \\\"quoted\\\" \\$HOME \\\\\"\n",
+		},
+		{
+			format: ShellFormat,
+			vars:   map[string]string{"B": "it's $HOME `id`", "A": "line1\nline2"},
+			want:   "export A='line1\nline2'\nexport B='it'\\''s $HOME `id`'\n",
+		},
+		{
+			format: DockerEnvFormat,
+			vars:   map[string]string{"B": `it's "quoted" $HOME`, "A": " spaced "},
+			want:   "A= spaced \nB=it's \"quoted\" $HOME\n",
+		},
+		{
+			format: JSONFormat,
+			vars:   map[string]string{"B": "<a>&\"\n", "A": "x"},
+			want:   "{\n  \"A\": \"x\",\n  \"B\": \"<a>&\\\"\\n\"\n}\n",
+		},
+		{
+			format: YAMLFormat,
+			vars:   map[string]string{"B": "yes", "A": "1"},
+			want:   "A: \"1\"\nB: \"yes\"\n",
+		},
+		{format: YAMLFormat, vars: map[string]string{}, want: "{}\n"},
+		{format: JSONFormat, vars: map[string]string{}, want: "{}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := Encode(tt.format, tt.vars)
+			errs.AssertNoError(t, err)
+			if string(got) != tt.want {
+				t.Errorf("Encode(%s) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	tests := []struct {
+		format string
+		decode func(data []byte) (map[string]string, error)
+	}{
+		{format: DotenvFormat, decode: func(data []byte) (map[string]string, error) {
+			entries, err := dotenv.ParseString(string(data))
+			return dotenv.ToMap(entries), err
+		}},
+		{format: JSONFormat, decode: func(data []byte) (map[string]string, error) {
+			vars := make(map[string]string)
+			return vars, json.Unmarshal(data, &vars)
+		}},
+		{format: YAMLFormat, decode: func(data []byte) (map[string]string, error) {
+			vars := make(map[string]string)
+			return vars, yaml.Unmarshal(data, &vars)
+		}},
+	}
+
+	vars := map[string]string{"NEWLINES": "a\nb\r\nc", "NUMBER": "0123", "BOOL": "true", "NULL": "null"}
+	for name, value := range tricky {
+		vars[name] = value
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			data, err := Encode(tt.format, vars)
+			errs.AssertNoError(t, err)
+
+			got, err := tt.decode(data)
+			errs.AssertNoError(t, err)
+			if !reflect.DeepEqual(got, vars) {
+				t.Errorf("decoded %s = %q, want %q", tt.format, got, vars)
+			}
+		})
+	}
+}
+
+func TestEncode_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		vars   map[string]string
+	}{
+		{name: "unknown format", format: "toml", vars: tricky},
+		{name: "invalid shell name", format: ShellFormat, vars: map[string]string{"1BAD": "x"}},
+		{name: "shell name with dash", format: ShellFormat, vars: map[string]string{"BAD-NAME": "x"}},
+		{name: "docker name with equals", format: DockerEnvFormat, vars: map[string]string{"A=B": "x"}},
+		{name: "docker multi-line value", format: DockerEnvFormat, vars: map[string]string{"A": "line1\nline2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Encode(tt.format, tt.vars)
+			errs.AssertErrorCode(t, err, error_codes.ValidationErrCode)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package envtemplate
+
+import (
+	"encoding/base64"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/dotenv"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// Renderer executes .env.template files against a project's resolved secrets.
+// Rendering happens entirely in memory.
+type Renderer struct {
+	secrets    map[string]string
+	unresolved map[string]error
+}
+
+// New creates a renderer for the given resolved secrets. unresolved maps logical
+// names that failed to resolve to their error, which is reported only if the
+// template actually uses that secret. It may be nil.
+func New(secrets map[string]string, unresolved map[string]error) *Renderer {
+	return &Renderer{secrets: secrets, unresolved: unresolved}
+}
+
+// Render executes the template text and returns the rendered output. The dot
+// is the map of logical names to resolved values, so {{ .DATABASE_URL }} is
+// the DATABASE_URL secret. Names without a value fail rendering, while
+// {{ index . "NAME" | default "fallback" }} falls back instead.
+func (r *Renderer) Render(name, text string) (string, error) {
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(r.funcs()).
+		Parse(text)
+	if err != nil {
+		return "", errs.Wrap(err, error_codes.TemplateErrCode, "failed to parse template").WithFile(name)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, r.secrets); err != nil {
+		return "", errs.Wrap(r.missingKeyErr(err), error_codes.TemplateErrCode, "failed to render template").WithFile(name)
+	}
+
+	return out.String(), nil
+}
+
+// missingKeyPattern matches the error text/template reports for a missing map key
+var missingKeyPattern = regexp.MustCompile(`map has no entry for key "([^"]*)"`)
+
+// missingKeyErr replaces the error for a secret missing from the dot with the
+// reason the secret failed to resolve, when it did
+func (r *Renderer) missingKeyErr(err error) error {
+	match := missingKeyPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+
+	if _, resolveErr := r.secret(match[1]); resolveErr != nil {
+		return resolveErr
+	}
+	return err
+}
+
+// RenderVars renders the template and parses the output as dotenv assignments
+func (r *Renderer) RenderVars(name, text string) (map[string]string, error) {
+	rendered, err := r.Render(name, text)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := dotenv.ParseString(rendered)
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.TemplateErrCode, "rendered template is not a valid env file").WithFile(name)
+	}
+
+	return dotenv.ToMap(entries), nil
+}
+
+func (r *Renderer) secret(name string) (string, error) {
+	if err, ok := r.unresolved[name]; ok {
+		return "", err
+	}
+
+	value, ok := r.secrets[name]
+	if !ok {
+		return "", errs.New(error_codes.SecretNotFoundErrCode, "secret is not mapped in project").WithContext("logical_name", name)
+	}
+
+	return value, nil
+}
+
+func (r *Renderer) funcs() template.FuncMap {
+	return template.FuncMap{
+		"secret":   r.secret,
+		"env":      os.Getenv,
+		"default":  defaultValue,
+		"required": required,
+		"base64":   encodeBase64,
+		"quote":    dotenv.Quote,
+	}
+}
+
+// defaultValue returns value, or fallback when value is empty. The fallback
+// comes first so it can be used in pipelines: {{ env "PORT" | default "8080" }}
+func defaultValue(fallback, value string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// required fails rendering with message when value is empty
+func required(message, value string) (string, error) {
+	if value == "" {
+		return "", errs.New(error_codes.TemplateErrCode, message)
+	}
+	return value, nil
+}
+
+func encodeBase64(value string) string {
+	return base64.StdEncoding.EncodeToString([]byte(value))
+}
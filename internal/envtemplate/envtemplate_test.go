@@ -0,0 +1,96 @@
+package envtemplate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+var errUnresolved = errs.New(error_codes.VaultNotFoundErrCode, "vault alias is not linked to workspace")
+
+func newRenderer() *Renderer {
+	return New(
+		map[string]string{
+			"DATABASE_URL": "postgres://db",
+			"EMPTY":        "",
+			"TRICKY":       "a \"b\" $HOME\\c\n\td\x01 ",
+		},
+		map[string]error{"BROKEN": errUnresolved},
+	)
+}
+
+func TestRender(t *testing.T) {
+	t.Setenv("KNOX_TEST_PORT", "9090")
+	t.Setenv("KNOX_TEST_UNSET", "")
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "dot", text: "URL={{ .DATABASE_URL }}", want: "URL=postgres://db"},
+		{name: "secret", text: `URL={{ secret "DATABASE_URL" }}`, want: "URL=postgres://db"},
+		{name: "env", text: `PORT={{ env "KNOX_TEST_PORT" }}`, want: "PORT=9090"},
+		{name: "default used", text: `PORT={{ env "KNOX_TEST_UNSET" | default "8080" }}`, want: "PORT=8080"},
+		{name: "default skipped", text: `PORT={{ env "KNOX_TEST_PORT" | default "8080" }}`, want: "PORT=9090"},
+		{name: "default for empty secret", text: `E={{ .EMPTY | default "none" }}`, want: "E=none"},
+		{name: "required", text: `URL={{ .DATABASE_URL | required "url is required" }}`, want: "URL=postgres://db"},
+		{name: "base64", text: `B={{ secret "DATABASE_URL" | base64 }}`, want: "B=cG9zdGdyZXM6Ly9kYg=="},
+		{name: "quote", text: `Q={{ .DATABASE_URL | quote }}`, want: `Q="postgres://db"`},
+	}
+
+	r := newRenderer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Render("test.env.template", tt.text)
+			errs.AssertNoError(t, err)
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		cause   error
+		message string
+	}{
+		{name: "missing key", text: "X={{ .NOPE }}", message: "NOPE"},
+		{name: "unresolved key", text: "X={{ .BROKEN }}", cause: errUnresolved},
+		{name: "unresolved secret", text: `X={{ secret "BROKEN" }}`, cause: errUnresolved},
+		{name: "required empty", text: `X={{ .EMPTY | required "empty is required" }}`, message: "empty is required"},
+		{name: "parse error", text: "X={{ .DATABASE_URL", message: "failed to parse template"},
+	}
+
+	r := newRenderer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.Render("test.env.template", tt.text)
+			errs.AssertErrorCode(t, err, error_codes.TemplateErrCode)
+			if tt.cause != nil && !errors.Is(err, tt.cause) {
+				t.Errorf("Render() error = %v, want it to wrap %v", err, tt.cause)
+			}
+			if tt.message != "" {
+				errs.AssertErrorContains(t, err, tt.message)
+			}
+		})
+	}
+}
+
+func TestRenderVars_QuoteRoundTrip(t *testing.T) {
+	r := newRenderer()
+
+	got, err := r.RenderVars("test.env.template", "URL={{ .DATABASE_URL | quote }}\nTRICKY={{ .TRICKY | quote }}\n")
+	errs.AssertNoError(t, err)
+
+	want := map[string]string{"URL": "postgres://db", "TRICKY": "a \"b\" $HOME\\c\n\td\x01 "}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RenderVars() = %q, want %q", got, want)
+	}
+}
@@ -11,13 +11,25 @@ const (
 	SecretExistsErrCode   errs.Code = "SECRET_EXISTS"
 	SecretInvalidErrCode  errs.Code = "SECRET_INVALID"
 
+	CollectionNotFoundErrCode errs.Code = "COLLECTION_NOT_FOUND"
+	CollectionExistsErrCode   errs.Code = "COLLECTION_EXISTS"
+	CollectionNotEmptyErrCode errs.Code = "COLLECTION_NOT_EMPTY"
+	CollectionInvalidErrCode  errs.Code = "COLLECTION_INVALID"
+
 	VaultCreationErrCode   errs.Code = "VAULT_CREATION"
 	VaultConnectionErrCode errs.Code = "VAULT_CONNECTION"
 	VaultIntegrityErrCode  errs.Code = "VAULT_INTEGRITY"
-
-	FileNotFoundErrCode     errs.Code = "FILE_NOT_FOUND"
-	FilePermissionErrCode   errs.Code = "FILE_PERMISSION"
-	DirectoryInvalidErrCode errs.Code = "DIRECTORY_INVALID"
+	VaultNotFoundErrCode   errs.Code = "VAULT_NOT_FOUND"
+	VaultExistsErrCode     errs.Code = "VAULT_EXISTS"
+	VaultLockedErrCode     errs.Code = "VAULT_LOCKED"
+	VaultPassphraseErrCode errs.Code = "VAULT_PASSPHRASE"
+	VaultEncryptionErrCode errs.Code = "VAULT_ENCRYPTION"
+	VaultInUseErrCode      errs.Code = "VAULT_IN_USE"
+
+	FileNotFoundErrCode        errs.Code = "FILE_NOT_FOUND"
+	FilePermissionErrCode      errs.Code = "FILE_PERMISSION"
+	InsecurePermissionsErrCode errs.Code = "INSECURE_PERMISSIONS"
+	DirectoryInvalidErrCode    errs.Code = "DIRECTORY_INVALID"
 
 	ValidationErrCode errs.Code = "VALIDATION"
 
@@ -26,4 +38,15 @@ const (
 	DatabaseFailureErrCode errs.Code = "DATABASE_FAILURE"
 
 	DatasourceErrCode errs.Code = "DATASOURCE_ERROR"
+
+	ResolutionFailureErrCode errs.Code = "RESOLUTION_FAILURE"
+	ProcessFailureErrCode    errs.Code = "PROCESS_FAILURE"
+	TemplateErrCode          errs.Code = "TEMPLATE_ERROR"
+
+	SettingUnknownErrCode errs.Code = "SETTING_UNKNOWN"
+	SettingInvalidErrCode errs.Code = "SETTING_INVALID"
+
+	SnapshotFailedErrCode   errs.Code = "SNAPSHOT_FAILED"
+	SnapshotNotFoundErrCode errs.Code = "SNAPSHOT_NOT_FOUND"
+	SnapshotChangedErrCode  errs.Code = "SNAPSHOT_CHANGED"
 )
@@ -0,0 +1,42 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Problem describes a single vault, collection or secret that could not be resolved
+// along with the logical names that depend on it
+type Problem struct {
+	Reference string
+	Names     []string
+	Err       error
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s (%s): %v", p.Reference, strings.Join(p.Names, ", "), p.Err)
+}
+
+// Problems aggregates every problem found while resolving a project. It is
+// the cause of the RESOLUTION_FAILURE error returned by Resolve.
+type Problems []Problem
+
+func (p Problems) Error() string {
+	var msg strings.Builder
+
+	for _, problem := range p {
+		msg.WriteString("\n  - ")
+		msg.WriteString(problem.String())
+	}
+
+	return msg.String()
+}
+
+// Unwrap exposes the underlying problems to errors.Is and errors.As
+func (p Problems) Unwrap() []error {
+	errors := make([]error, 0, len(p))
+	for _, problem := range p {
+		errors = append(errors, problem.Err)
+	}
+	return errors
+}
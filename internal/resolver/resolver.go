@@ -0,0 +1,153 @@
+package resolver
+
+import (
+	"sort"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// UnlockFunc is called for every vault opened during resolution so encrypted
+// vaults can be unlocked before their values are read
+type UnlockFunc func(alias string, v *vault.Vault) error
+
+// Resolver turns a project's secret map into concrete values using the vaults
+// linked to a workspace
+type Resolver struct {
+	ws     *workspace.Workspace
+	unlock UnlockFunc
+}
+
+// New creates a resolver for the given workspace. unlock may be nil when all
+// linked vaults are expected to be plaintext or already unlocked.
+func New(ws *workspace.Workspace, unlock UnlockFunc) *Resolver {
+	return &Resolver{ws: ws, unlock: unlock}
+}
+
+// binding is a logical name together with its parsed secret reference
+type binding struct {
+	name string
+	ref  *workspace.SecretReference
+}
+
+// Resolve returns a map of logical secret name to value for every entry in the
+// project's secret map. Resolution does not stop at the first failure: every
+// missing vault, collection or secret is collected into a single *Error, and
+// the values that could be resolved are still returned alongside it.
+func (r *Resolver) Resolve(project *workspace.Project) (map[string]string, error) {
+	values := make(map[string]string, len(project.SecretMap))
+	var problems []Problem
+
+	byVault := make(map[string][]binding)
+	for _, name := range sortedNames(project.SecretMap) {
+		raw := project.SecretMap[name]
+
+		ref, err := workspace.ParseSecretReference(raw)
+		if err != nil {
+			problems = append(problems, Problem{Reference: raw, Names: []string{name}, Err: err})
+			continue
+		}
+
+		byVault[ref.Vault] = append(byVault[ref.Vault], binding{name: name, ref: ref})
+	}
+
+	aliases := make([]string, 0, len(byVault))
+	for alias := range byVault {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		problems = append(problems, r.resolveVault(alias, byVault[alias], values)...)
+	}
+
+	if len(problems) > 0 {
+		return values, errs.Wrap(Problems(problems), error_codes.ResolutionFailureErrCode, "failed to resolve project '%s' (%d problems)", project.Name, len(problems))
+	}
+
+	return values, nil
+}
+
+// resolveVault reads every binding that lives in the vault linked under alias.
+// A vault or collection that cannot be used is reported once for all of the
+// names that depend on it.
+func (r *Resolver) resolveVault(alias string, bindings []binding, values map[string]string) []Problem {
+	v, err := r.ws.OpenVault(alias)
+	if err != nil {
+		return []Problem{{Reference: alias, Names: bindingNames(bindings), Err: err}}
+	}
+	defer func(v *vault.Vault) {
+		_ = v.Close()
+	}(v)
+
+	if r.unlock != nil {
+		if err := r.unlock(alias, v); err != nil {
+			return []Problem{{Reference: alias, Names: bindingNames(bindings), Err: err}}
+		}
+	}
+
+	byCollection := make(map[string][]binding)
+	var collections []string
+	for _, b := range bindings {
+		if _, ok := byCollection[b.ref.Collection]; !ok {
+			collections = append(collections, b.ref.Collection)
+		}
+		byCollection[b.ref.Collection] = append(byCollection[b.ref.Collection], b)
+	}
+	sort.Strings(collections)
+
+	var problems []Problem
+	for _, collection := range collections {
+		for i, b := range byCollection[collection] {
+			secret, err := getSecret(v, b.ref)
+			if err == nil {
+				values[b.name] = secret.Value
+				continue
+			}
+
+			if errs.Is(err, vault.ErrCollectionNotFound.Code) {
+				problems = append(problems, Problem{
+					Reference: collection + "@" + alias,
+					Names:     bindingNames(byCollection[collection][i:]),
+					Err:       err,
+				})
+				break
+			}
+
+			problems = append(problems, Problem{
+				Reference: b.ref.String(),
+				Names:     []string{b.name},
+				Err:       err,
+			})
+		}
+	}
+
+	return problems
+}
+
+// getSecret reads the version of the secret the reference points at
+func getSecret(v *vault.Vault, ref *workspace.SecretReference) (*vault.Secret, error) {
+	if ref.Version > 0 {
+		return v.GetSecretVersion(ref.Collection, ref.Secret, ref.Version)
+	}
+	return v.GetSecret(ref.Collection, ref.Secret)
+}
+
+func bindingNames(bindings []binding) []string {
+	names := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		names = append(names, b.name)
+	}
+	return names
+}
+
+func sortedNames(secretMap map[string]string) []string {
+	names := make([]string, 0, len(secretMap))
+	for name := range secretMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,158 @@
+package resolver
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// newWorkspace creates a workspace with a plaintext vault linked as "v1". The
+// vault holds API_KEY at versions 1 and 2 and DB_URL in the global collection.
+func newWorkspace(t *testing.T) *workspace.Workspace {
+	t.Helper()
+
+	dir := t.TempDir()
+	ws, err := workspace.CreateWorkspace(dir)
+	errs.AssertNoError(t, err)
+
+	vaultPath := filepath.Join(dir, "v1.db")
+	v, err := vault.Create(vaultPath, vault.CreateOptions{})
+	errs.AssertNoError(t, err)
+	defer func() {
+		_ = v.Close()
+	}()
+
+	errs.AssertNoError(t, v.SetSecret("global", "API_KEY", "key-v1", false))
+	errs.AssertNoError(t, v.SetSecret("global", "API_KEY", "key-v2", true))
+	errs.AssertNoError(t, v.SetSecret("global", "DB_URL", "postgres://db", false))
+
+	errs.AssertNoError(t, ws.LinkVault("v1", vaultPath))
+
+	return ws
+}
+
+func TestResolve(t *testing.T) {
+	type problem struct {
+		reference string
+		names     []string
+		code      errs.Code
+	}
+
+	tests := []struct {
+		name      string
+		secretMap map[string]string
+		want      map[string]string
+		problems  []problem
+	}{
+		{
+			name: "all resolved",
+			secretMap: map[string]string{
+				"API_KEY":  "API_KEY@v1/global",
+				"DATABASE": "DB_URL@v1/global",
+			},
+			want: map[string]string{"API_KEY": "key-v2", "DATABASE": "postgres://db"},
+		},
+		{
+			name:      "pinned version",
+			secretMap: map[string]string{"OLD_KEY": "API_KEY@v1/global#1", "NEW_KEY": "API_KEY@v1/global#2"},
+			want:      map[string]string{"OLD_KEY": "key-v1", "NEW_KEY": "key-v2"},
+		},
+		{
+			name:      "missing pinned version",
+			secretMap: map[string]string{"API_KEY": "API_KEY@v1/global#3"},
+			want:      map[string]string{},
+			problems: []problem{
+				{reference: "API_KEY@v1/global#3", names: []string{"API_KEY"}, code: error_codes.SecretNotFoundErrCode},
+			},
+		},
+		{
+			name:      "unlinked vault alias",
+			secretMap: map[string]string{"A": "A@nope/global", "B": "B@nope/global"},
+			want:      map[string]string{},
+			problems: []problem{
+				{reference: "nope", names: []string{"A", "B"}, code: error_codes.VaultNotFoundErrCode},
+			},
+		},
+		{
+			name: "missing collection reported once",
+			secretMap: map[string]string{
+				"A": "A@v1/missing",
+				"B": "B@v1/missing",
+				"C": "C@v1/missing",
+			},
+			want: map[string]string{},
+			problems: []problem{
+				{reference: "missing@v1", names: []string{"A", "B", "C"}, code: error_codes.CollectionNotFoundErrCode},
+			},
+		},
+		{
+			name:      "missing key",
+			secretMap: map[string]string{"MISSING": "MISSING@v1/global"},
+			want:      map[string]string{},
+			problems: []problem{
+				{reference: "MISSING@v1/global", names: []string{"MISSING"}, code: error_codes.SecretNotFoundErrCode},
+			},
+		},
+		{
+			name: "partial values",
+			secretMap: map[string]string{
+				"API_KEY": "API_KEY@v1/global",
+				"MISSING": "MISSING@v1/global",
+				"REMOTE":  "TOKEN@nope/global",
+			},
+			want: map[string]string{"API_KEY": "key-v2"},
+			problems: []problem{
+				{reference: "nope", names: []string{"REMOTE"}, code: error_codes.VaultNotFoundErrCode},
+				{reference: "MISSING@v1/global", names: []string{"MISSING"}, code: error_codes.SecretNotFoundErrCode},
+			},
+		},
+	}
+
+	ws := newWorkspace(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := workspace.NewProject("test", "")
+			for name, ref := range tt.secretMap {
+				project.AddSecret(name, ref)
+			}
+
+			got, err := New(ws, nil).Resolve(project)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() values = %v, want %v", got, tt.want)
+			}
+
+			if len(tt.problems) == 0 {
+				errs.AssertNoError(t, err)
+				return
+			}
+
+			errs.AssertErrorCode(t, err, error_codes.ResolutionFailureErrCode)
+
+			var problems Problems
+			if !errors.As(err, &problems) {
+				t.Fatalf("Resolve() error = %v, want Problems as the cause", err)
+			}
+			if len(problems) != len(tt.problems) {
+				t.Fatalf("Resolve() problems = %v, want %d", problems, len(tt.problems))
+			}
+
+			for i, want := range tt.problems {
+				p := problems[i]
+				if p.Reference != want.reference {
+					t.Errorf("problem %d reference = %q, want %q", i, p.Reference, want.reference)
+				}
+				if !reflect.DeepEqual(p.Names, want.names) {
+					t.Errorf("problem %d names = %v, want %v", i, p.Names, want.names)
+				}
+				errs.AssertErrorCode(t, p.Err, want.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// forwardedSignals are relayed from knox to the child process
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// BuildEnv returns a child environment holding vars plus any parent variables
+// matching allowlist. Allowlist entries are exact names or prefixes ending in '*'.
+// Values in vars take precedence over inherited ones.
+func BuildEnv(vars map[string]string, allowlist []string) []string {
+	merged := make(map[string]string, len(vars))
+
+	for _, entry := range os.Environ() {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !isAllowed(name, allowlist) {
+			continue
+		}
+		merged[name] = value
+	}
+
+	for name, value := range vars {
+		merged[name] = value
+	}
+
+	names := make([]string, 0, len(merged))
+	for name := range merged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		env = append(env, name+"="+merged[name])
+	}
+
+	return env
+}
+
+// Run executes argv with env, wired to the current stdio. Signals received by
+// knox are forwarded to the child and the child's exit code is returned. A
+// child terminated by a signal reports 128 plus the signal number, as shells do.
+func Run(argv []string, env []string) (int, error) {
+	if len(argv) == 0 {
+		return 0, errs.New(error_codes.ValidationErrCode, "no command given")
+	}
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return 0, errs.Wrap(err, error_codes.ProcessFailureErrCode, "failed to start command").WithContext("command", argv[0])
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	if err == nil {
+		return 0, nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 0, errs.Wrap(err, error_codes.ProcessFailureErrCode, "failed to wait for command").WithContext("command", argv[0])
+	}
+
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal()), nil
+	}
+
+	return exitErr.ExitCode(), nil
+}
+
+func isAllowed(name string, allowlist []string) bool {
+	for _, allowed := range allowlist {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+			continue
+		}
+		if name == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,329 @@
+// Package snapshot keeps copies of vault databases and project files taken
+// before destructive operations, so the most recent operation can be undone.
+// Snapshots are stored per workspace under the XDG state directory.
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/tomdoesdev/knox/kit/fs"
+	"github.com/tomdoesdev/knox/kit/xdg"
+)
+
+const (
+	appName          = "knox"
+	snapshotsDirName = "snapshots"
+	manifestName     = "manifest.json"
+
+	// missingState is the state of a file that does not exist
+	missingState = "missing"
+
+	// uncommittedGrace is how long a snapshot directory without a manifest is
+	// left alone by prune, since another process may still be writing it
+	uncommittedGrace = time.Hour
+)
+
+// Kinds of file a snapshot holds
+const (
+	VaultFile   = "vault"
+	ProjectFile = "project"
+)
+
+// File is a single file captured in a snapshot
+type File struct {
+	Kind string `json:"kind"`
+	// Name is the vault alias or project name
+	Name string `json:"name"`
+	// Path is where the file is restored to
+	Path string `json:"path"`
+	// Stored is the name of the copy inside the snapshot directory
+	Stored string `json:"stored"`
+	// State identifies the contents of the file at Path right after the
+	// operation, so undo can tell whether it was changed since
+	State string `json:"state,omitempty"`
+}
+
+// Snapshot is a set of files captured before one operation
+type Snapshot struct {
+	ID        string    `json:"id"`
+	Operation string    `json:"operation"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []File    `json:"files"`
+
+	dir string
+}
+
+// Store holds the snapshots of one workspace, keeping at most retention of them
+type Store struct {
+	dir       string
+	retention int
+}
+
+// Open returns the snapshot store of the workspace in workspaceDir. A
+// retention of 0 disables snapshots.
+func Open(workspaceDir string, retention int) (*Store, error) {
+	stateDir, err := xdg.StateDir(appName)
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to open state directory, set HOME or XDG_STATE_HOME")
+	}
+
+	sum := sha256.Sum256([]byte(filepath.Clean(workspaceDir)))
+	dir := filepath.Join(stateDir, snapshotsDirName, hex.EncodeToString(sum[:8]))
+
+	return &Store{dir: dir, retention: retention}, nil
+}
+
+// Enabled reports whether snapshots are taken at all
+func (s *Store) Enabled() bool {
+	return s.retention > 0
+}
+
+// Begin starts a snapshot for operation. Files are added to it with AddVault
+// and AddProject, and it only becomes visible once committed.
+func (s *Store) Begin(operation string) (*Snapshot, error) {
+	now := time.Now().UTC()
+	id := fmt.Sprintf("%s-%09d", now.Format("20060102T150405"), now.Nanosecond())
+
+	dir := filepath.Join(s.dir, id)
+	if err := fs.MkdirAll(dir, 0700); err != nil {
+		return nil, errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to create snapshot directory")
+	}
+
+	return &Snapshot{ID: id, Operation: operation, CreatedAt: now, dir: dir}, nil
+}
+
+// AddVault captures a consistent copy of the open vault v, linked under alias at path
+func (sn *Snapshot) AddVault(alias, path string, v *vault.Vault) error {
+	stored := fmt.Sprintf("%s-%s.db", VaultFile, alias)
+	if err := v.Backup(filepath.Join(sn.dir, stored)); err != nil {
+		return errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to snapshot vault").WithContext("alias", alias)
+	}
+
+	sn.Files = append(sn.Files, File{Kind: VaultFile, Name: alias, Path: path, Stored: stored})
+	return nil
+}
+
+// AddProject captures a copy of the project file at path
+func (sn *Snapshot) AddProject(name, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to read project file").WithPath(path)
+	}
+
+	stored := fmt.Sprintf("%s-%s.json", ProjectFile, name)
+	if err := os.WriteFile(filepath.Join(sn.dir, stored), data, 0600); err != nil {
+		return errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to snapshot project").WithContext("project", name)
+	}
+
+	sn.Files = append(sn.Files, File{Kind: ProjectFile, Name: name, Path: path, Stored: stored})
+	return nil
+}
+
+// Commit records the snapshot along with the current state of every file it
+// captured, and removes the oldest snapshots beyond the store's retention. It
+// is called once the operation has changed the files.
+func (s *Store) Commit(sn *Snapshot) error {
+	for i := range sn.Files {
+		state, err := fileState(sn.Files[i])
+		if err != nil {
+			return err
+		}
+		sn.Files[i].State = state
+	}
+
+	data, err := json.MarshalIndent(sn, "", "  ")
+	if err != nil {
+		return errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to encode snapshot manifest")
+	}
+
+	if err := fs.WriteFileAtomic(filepath.Join(sn.dir, manifestName), data, 0600); err != nil {
+		return errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to write snapshot manifest")
+	}
+
+	return s.prune()
+}
+
+// Discard removes a snapshot that was begun but is no longer needed
+func (s *Store) Discard(sn *Snapshot) {
+	_ = os.RemoveAll(sn.dir)
+}
+
+// List returns the committed snapshots, newest first
+func (s *Store) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to read snapshots directory").WithPath(s.dir)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		sn, err := s.load(entry.Name())
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, *sn)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].ID > snapshots[j].ID
+	})
+
+	return snapshots, nil
+}
+
+// Latest returns the most recent snapshot
+func (s *Store) Latest() (*Snapshot, error) {
+	snapshots, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, errs.New(error_codes.SnapshotNotFoundErrCode, "no snapshots to undo")
+	}
+
+	return &snapshots[0], nil
+}
+
+// Changed returns the files of sn that were modified after the snapshot was
+// committed. Restoring sn would discard those modifications. Files of
+// snapshots taken before states were recorded are never reported.
+func (s *Store) Changed(sn *Snapshot) ([]File, error) {
+	var changed []File
+	for _, f := range sn.Files {
+		if f.State == "" {
+			continue
+		}
+
+		state, err := fileState(f)
+		if err != nil {
+			return nil, err
+		}
+		if state != f.State {
+			changed = append(changed, f)
+		}
+	}
+
+	return changed, nil
+}
+
+// fileState identifies the current contents of the file f is restored to
+func fileState(f File) (string, error) {
+	if !fs.IsExist(f.Path) {
+		return missingState, nil
+	}
+
+	switch f.Kind {
+	case VaultFile:
+		state, err := vault.ReadState(f.Path)
+		if err != nil {
+			return "", errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to read vault state").WithContext("alias", f.Name)
+		}
+		return state, nil
+	default:
+		data, err := os.ReadFile(f.Path)
+		if err != nil {
+			return "", errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to read file state").WithPath(f.Path)
+		}
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:]), nil
+	}
+}
+
+// Restore puts every file of sn back in place and then removes sn, so that
+// restoring repeatedly walks back through the snapshots. Vaults must not be
+// open while they are restored.
+func (s *Store) Restore(sn *Snapshot) error {
+	for _, f := range sn.Files {
+		stored := filepath.Join(sn.dir, f.Stored)
+
+		switch f.Kind {
+		case VaultFile:
+			if err := vault.Restore(stored, f.Path); err != nil {
+				return errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to restore vault").WithContext("alias", f.Name)
+			}
+		case ProjectFile:
+			data, err := os.ReadFile(stored)
+			if err != nil {
+				return errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to read snapshot").WithPath(stored)
+			}
+			if err := fs.WriteFileAtomic(f.Path, data, 0600); err != nil {
+				return errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to restore project").WithContext("project", f.Name)
+			}
+		default:
+			return errs.New(error_codes.SnapshotFailedErrCode, "unknown file kind in snapshot").
+				WithContext("snapshot", sn.ID).
+				WithContext("kind", f.Kind)
+		}
+	}
+
+	s.Discard(sn)
+	return nil
+}
+
+func (s *Store) load(id string) (*Snapshot, error) {
+	dir := filepath.Join(s.dir, id)
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	var sn Snapshot
+	if err := json.Unmarshal(data, &sn); err != nil {
+		return nil, err
+	}
+	sn.dir = dir
+
+	return &sn, nil
+}
+
+// prune removes the oldest snapshots beyond the retention, along with
+// directories of snapshots that were never committed and have not changed
+// within uncommittedGrace
+func (s *Store) prune() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return errs.Wrap(err, error_codes.SnapshotFailedErrCode, "failed to read snapshots directory").WithPath(s.dir)
+	}
+
+	var committed []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if fs.IsFile(filepath.Join(s.dir, entry.Name(), manifestName)) {
+			committed = append(committed, entry.Name())
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < uncommittedGrace {
+			continue
+		}
+		_ = os.RemoveAll(filepath.Join(s.dir, entry.Name()))
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(committed)))
+	for i := s.retention; i < len(committed); i++ {
+		_ = os.RemoveAll(filepath.Join(s.dir, committed[i]))
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package vault
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// Backup writes a consistent snapshot of the vault to dest using VACUUM INTO,
+// which is safe while other connections are writing to the vault. dest must
+// not exist yet and is created with 0600 permissions.
+func (v *Vault) Backup(dest string) error {
+	f, err := os.OpenFile(dest, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return errs.New(ErrVaultExists.Code, "a file already exists at backup path").WithPath(dest)
+		}
+		return errs.Wrap(err, ErrVaultCreationFailed.Code, "failed to create backup file").WithPath(dest)
+	}
+	if err := f.Close(); err != nil {
+		removeVaultFiles(dest)
+		return errs.Wrap(err, ErrVaultCreationFailed.Code, "failed to create backup file").WithPath(dest)
+	}
+
+	// VACUUM INTO accepts an existing empty file, which keeps the permissions set above
+	if _, err := v.db.Exec("VACUUM INTO ?", dest); err != nil {
+		removeVaultFiles(dest)
+		return errs.Wrap(err, ErrVaultCreationFailed.Code, "failed to back up vault").WithPath(dest)
+	}
+
+	return nil
+}
+
+// CheckIntegrity verifies that path holds a vault and that SQLite's integrity
+// check passes on it. The file is opened read only.
+func CheckIntegrity(path string) error {
+	isVault, err := IsVault(path)
+	if err != nil {
+		return err
+	}
+	if !isVault {
+		return errs.New(ErrDatasourceUnreachable.Code, "no valid vault found at path").WithContext("path", path)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return errs.Wrap(err, ErrVaultConnectionFailed.Code, "failed to open database").WithContext("path", path)
+	}
+	defer func(db *sql.DB) {
+		_ = db.Close()
+	}(db)
+
+	var result string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return errs.Wrap(err, ErrVaultIntegrityCheck.Code, "failed to run integrity check").WithContext("path", path)
+	}
+	if result != "ok" {
+		return errs.New(ErrVaultIntegrityCheck.Code, "vault failed integrity check").
+			WithContext("path", path).
+			WithContext("result", result)
+	}
+
+	return nil
+}
+
+// Restore replaces the vault file at dest with the snapshot at src. The
+// snapshot is checked, copied next to dest and checked again before it is
+// renamed over dest, so dest is either the old or the new vault but never a
+// mix of the two. The vault at dest must not be open while it is restored.
+func Restore(src, dest string) error {
+	if err := CheckIntegrity(src); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".restore-*")
+	if err != nil {
+		return errs.Wrap(err, ErrVaultCreationFailed.Code, "failed to create restore file").WithPath(dest)
+	}
+	tmpPath := tmp.Name()
+	defer removeVaultFiles(tmpPath)
+
+	if err := copySnapshot(tmp, src); err != nil {
+		return errs.Wrap(err, ErrVaultCreationFailed.Code, "failed to copy snapshot").WithPath(src)
+	}
+
+	if err := CheckIntegrity(tmpPath); err != nil {
+		return err
+	}
+
+	if err := checkpoint(dest); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, dest); err != nil {
+		return errs.Wrap(err, ErrVaultCreationFailed.Code, "failed to replace vault").WithPath(dest)
+	}
+
+	// Sidecars of the replaced vault must not be applied to the restored one
+	for _, p := range []string{dest + "-wal", dest + "-shm"} {
+		_ = os.Remove(p)
+	}
+
+	return nil
+}
+
+// copySnapshot copies src into tmp, syncing and closing tmp
+func copySnapshot(tmp *os.File, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	defer func(in *os.File) {
+		_ = in.Close()
+	}(in)
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	return tmp.Close()
+}
+
+// checkpoint folds the write-ahead log of the vault at path into the main
+// database file. A missing vault has nothing to checkpoint.
+func checkpoint(path string) error {
+	isVault, err := IsVault(path)
+	if err != nil || !isVault {
+		return nil
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return errs.Wrap(err, ErrVaultConnectionFailed.Code, "failed to open database").WithContext("path", path)
+	}
+	defer func(db *sql.DB) {
+		_ = db.Close()
+	}(db)
+
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to checkpoint vault").WithContext("path", path)
+	}
+
+	return nil
+}
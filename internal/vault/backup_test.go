@@ -0,0 +1,168 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// backupTestVault creates a vault holding A=value-a and backs it up, returning
+// the vault path and the backup path
+func backupTestVault(t *testing.T) (string, string) {
+	t.Helper()
+
+	v, path := createTestVault(t, testPassphrase)
+	errs.AssertNoError(t, v.SetSecret("global", "A", "value-a", false))
+
+	backup := filepath.Join(t.TempDir(), "backup.db")
+	errs.AssertNoError(t, v.Backup(backup))
+	errs.AssertNoError(t, v.Close())
+
+	return path, backup
+}
+
+// readSecret opens the vault at path and returns the value of key in global
+func readSecret(t *testing.T, path, key string) (string, error) {
+	t.Helper()
+
+	v, err := Open(NewPathDatasource(path))
+	errs.AssertNoError(t, err)
+	defer func() {
+		_ = v.Close()
+	}()
+	errs.AssertNoError(t, v.Unlock(testPassphrase))
+
+	secret, err := v.GetSecret("global", key)
+	if err != nil {
+		return "", err
+	}
+	return secret.Value, nil
+}
+
+func TestBackup(t *testing.T) {
+	path, backup := backupTestVault(t)
+
+	info, err := os.Stat(backup)
+	errs.AssertNoError(t, err)
+	if got := info.Mode().Perm(); got != 0600 {
+		t.Errorf("backup mode = %o, want 600", got)
+	}
+
+	errs.AssertNoError(t, CheckIntegrity(backup))
+
+	vaultID, err := ReadID(path)
+	errs.AssertNoError(t, err)
+	backupID, err := ReadID(backup)
+	errs.AssertNoError(t, err)
+	if vaultID == "" || backupID != vaultID {
+		t.Errorf("backup id = %q, want the vault id %q", backupID, vaultID)
+	}
+
+	value, err := readSecret(t, backup, "A")
+	errs.AssertNoError(t, err)
+	if value != "value-a" {
+		t.Errorf("backup value = %q, want %q", value, "value-a")
+	}
+
+	v, err := Open(NewPathDatasource(path))
+	errs.AssertNoError(t, err)
+	defer func() {
+		_ = v.Close()
+	}()
+	err = v.Backup(backup)
+	errs.AssertErrorCode(t, err, ErrVaultExists.Code)
+}
+
+func TestRestore(t *testing.T) {
+	path, backup := backupTestVault(t)
+
+	v, err := Open(NewPathDatasource(path))
+	errs.AssertNoError(t, err)
+	errs.AssertNoError(t, v.Unlock(testPassphrase))
+	errs.AssertNoError(t, v.SetSecret("global", "B", "value-b", false))
+	errs.AssertNoError(t, v.DeleteSecret("global", "A"))
+	errs.AssertNoError(t, v.Close())
+
+	errs.AssertNoError(t, Restore(backup, path))
+
+	value, err := readSecret(t, path, "A")
+	errs.AssertNoError(t, err)
+	if value != "value-a" {
+		t.Errorf("restored value = %q, want %q", value, "value-a")
+	}
+
+	_, err = readSecret(t, path, "B")
+	errs.AssertErrorCode(t, err, ErrSecretNotFound.Code)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	errs.AssertNoError(t, err)
+	for _, entry := range entries {
+		if entry.Name() != filepath.Base(path) && entry.Name() != filepath.Base(path)+"-wal" && entry.Name() != filepath.Base(path)+"-shm" {
+			t.Errorf("restore left %s behind", entry.Name())
+		}
+	}
+}
+
+func TestRestore_RejectsInvalidSnapshots(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, backup string)
+	}{
+		{
+			name: "not a vault",
+			modify: func(t *testing.T, backup string) {
+				errs.AssertNoError(t, os.WriteFile(backup, []byte("not a database"), 0600))
+			},
+		},
+		{
+			name: "corrupted pages",
+			modify: func(t *testing.T, backup string) {
+				data, err := os.ReadFile(backup)
+				errs.AssertNoError(t, err)
+				if len(data) < 8192 {
+					t.Fatalf("backup is only %d bytes", len(data))
+				}
+				// Keep the header intact so the file still looks like a vault
+				for i := 4096; i < len(data); i++ {
+					data[i] = 0xff
+				}
+				errs.AssertNoError(t, os.WriteFile(backup, data, 0600))
+			},
+		},
+		{
+			name: "missing",
+			modify: func(t *testing.T, backup string) {
+				errs.AssertNoError(t, os.Remove(backup))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, backup := backupTestVault(t)
+
+			before, err := os.ReadFile(path)
+			errs.AssertNoError(t, err)
+
+			tt.modify(t, backup)
+
+			if err := Restore(backup, path); err == nil {
+				t.Fatal("Restore() accepted an invalid snapshot")
+			}
+
+			after, err := os.ReadFile(path)
+			errs.AssertNoError(t, err)
+			if string(after) != string(before) {
+				t.Error("Restore() changed the vault after rejecting the snapshot")
+			}
+
+			value, err := readSecret(t, path, "A")
+			errs.AssertNoError(t, err)
+			if value != "value-a" {
+				t.Errorf("vault value = %q, want %q", value, "value-a")
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package vault
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// Collection groups secrets inside a vault
+type Collection struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	SecretCount int    `json:"secret_count"`
+}
+
+// CreateCollection adds an empty collection to the vault
+func (v *Vault) CreateCollection(name, description string) error {
+	if err := validateCollectionName(name); err != nil {
+		return err
+	}
+
+	_, err := v.db.Exec("INSERT INTO collections (name, description) VALUES (?, ?)", name, description)
+	if err != nil {
+		if isUniqueConstraintErr(err) {
+			return errs.New(ErrCollectionExists.Code, "collection already exists").WithContext("collection", name)
+		}
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to create collection").WithContext("collection", name)
+	}
+
+	return nil
+}
+
+// GetCollection returns the named collection
+func (v *Vault) GetCollection(name string) (*Collection, error) {
+	query := `
+		SELECT c.name, COALESCE(c.description, ''), COUNT(s.id)
+		FROM collections c LEFT JOIN secrets s ON s.collection_id = c.id
+		WHERE c.name = ?
+		GROUP BY c.id
+	`
+
+	var c Collection
+	err := v.db.QueryRow(query, name).Scan(&c.Name, &c.Description, &c.SecretCount)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.New(ErrCollectionNotFound.Code, "collection not found").WithContext("collection", name)
+		}
+		return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to get collection").WithContext("collection", name)
+	}
+
+	return &c, nil
+}
+
+// ListCollections returns every collection in the vault ordered by name
+func (v *Vault) ListCollections() ([]Collection, error) {
+	query := `
+		SELECT c.name, COALESCE(c.description, ''), COUNT(s.id)
+		FROM collections c LEFT JOIN secrets s ON s.collection_id = c.id
+		GROUP BY c.id
+		ORDER BY c.name
+	`
+
+	rows, err := v.db.Query(query)
+	if err != nil {
+		return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to query collections")
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var collections []Collection
+	for rows.Next() {
+		var c Collection
+		if err := rows.Scan(&c.Name, &c.Description, &c.SecretCount); err != nil {
+			return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to scan collection row")
+		}
+		collections = append(collections, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "error iterating collection rows")
+	}
+
+	return collections, nil
+}
+
+// RenameCollection changes the name of a collection. Secrets stay encrypted
+// under the collection's id, so they are unaffected.
+func (v *Vault) RenameCollection(name, newName string) error {
+	if err := validateCollectionName(newName); err != nil {
+		return err
+	}
+
+	result, err := v.db.Exec("UPDATE collections SET name = ? WHERE name = ?", newName, name)
+	if err != nil {
+		if isUniqueConstraintErr(err) {
+			return errs.New(ErrCollectionExists.Code, "collection already exists").WithContext("collection", newName)
+		}
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to rename collection").WithContext("collection", name)
+	}
+
+	return expectCollectionAffected(result, name)
+}
+
+// DescribeCollection sets the description of a collection
+func (v *Vault) DescribeCollection(name, description string) error {
+	result, err := v.db.Exec("UPDATE collections SET description = ? WHERE name = ?", description, name)
+	if err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to describe collection").WithContext("collection", name)
+	}
+
+	return expectCollectionAffected(result, name)
+}
+
+// DeleteCollection removes a collection. A collection that still holds secrets
+// is only deleted when force is set, in which case its secrets and their
+// history are deleted with it.
+func (v *Vault) DeleteCollection(name string, force bool) error {
+	collectionID, err := v.collectionID(name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := v.db.Begin()
+	if err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to begin transaction")
+	}
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	var count int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM secrets WHERE collection_id = ?", collectionID).Scan(&count); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to count collection secrets").WithContext("collection", name)
+	}
+	if count > 0 && !force {
+		return errs.New(ErrCollectionNotEmpty.Code, "collection is not empty").
+			WithContext("collection", name).
+			WithContext("secrets", count)
+	}
+
+	statements := []string{
+		"DELETE FROM secret_versions WHERE secret_id IN (SELECT id FROM secrets WHERE collection_id = ?)",
+		"DELETE FROM secrets WHERE collection_id = ?",
+		"DELETE FROM collections WHERE id = ?",
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt, collectionID); err != nil {
+			return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to delete collection").WithContext("collection", name)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to commit collection deletion").WithContext("collection", name)
+	}
+
+	return nil
+}
+
+func expectCollectionAffected(result sql.Result, name string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to update collection").WithContext("collection", name)
+	}
+	if affected == 0 {
+		return errs.New(ErrCollectionNotFound.Code, "collection not found").WithContext("collection", name)
+	}
+	return nil
+}
+
+// validateCollectionName rejects names that would make secret references ambiguous
+func validateCollectionName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errs.New(ErrCollectionInvalid.Code, "collection name cannot be empty")
+	}
+	if strings.ContainsAny(name, "@/# \t\n") {
+		return errs.New(ErrCollectionInvalid.Code, "collection name cannot contain '@', '/', '#' or whitespace").WithContext("collection", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package vault
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// CreateOptions configures a newly created vault
+type CreateOptions struct {
+	// Passphrase encrypts the vault at rest. An empty passphrase creates a plaintext vault.
+	Passphrase string
+}
+
+// Create creates a new vault file at path and returns it opened and, when a
+// passphrase is given, encrypted and unlocked. The parent directory must
+// already exist. Create fails if anything exists at path, and the file is
+// created with 0600 permissions and removed again if initialisation fails.
+// The caller is responsible for closing the vault.
+func Create(path string, opts CreateOptions) (*Vault, error) {
+	if path == "" {
+		return nil, errs.New(ErrDatasourcePathInvalid.Code, "vault path cannot be empty")
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return nil, errs.New(ErrVaultExists.Code, "a file already exists at vault path").WithPath(path)
+		}
+		return nil, errs.Wrap(err, ErrVaultCreationFailed.Code, "failed to create vault file").WithPath(path)
+	}
+	if err := f.Close(); err != nil {
+		removeVaultFiles(path)
+		return nil, errs.Wrap(err, ErrVaultCreationFailed.Code, "failed to create vault file").WithPath(path)
+	}
+
+	if err := createSqliteFile(path); err != nil {
+		removeVaultFiles(path)
+		return nil, err
+	}
+
+	v, err := Open(NewPathDatasource(path))
+	if err != nil {
+		removeVaultFiles(path)
+		return nil, err
+	}
+
+	if opts.Passphrase != "" {
+		if err := v.Encrypt(opts.Passphrase); err != nil {
+			_ = v.Close()
+			removeVaultFiles(path)
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+// removeVaultFiles deletes a partially created vault along with its SQLite sidecar files
+func removeVaultFiles(path string) {
+	for _, p := range []string{path, path + "-wal", path + "-shm", path + "-journal"} {
+		_ = os.Remove(p)
+	}
+}
@@ -0,0 +1,372 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+const (
+	cipherName     = "aes-256-gcm"
+	kdfName        = "pbkdf2-sha256"
+	kdfIterations  = 600_000
+	kdfSaltSize    = 16
+	keySize        = 32
+	sealedPrefix   = "enc:v1:"
+	keyCheckValue  = "knox"
+	keyCheckAAD    = "key_check"
+	metaCipher     = "cipher"
+	metaKDF        = "kdf"
+	metaIterations = "kdf_iterations"
+	metaSalt       = "kdf_salt"
+	metaKeyCheck   = "key_check"
+)
+
+// IsEncrypted reports whether secret values in the vault are encrypted at rest
+func (v *Vault) IsEncrypted() bool {
+	return v.encrypted
+}
+
+// IsLocked reports whether the vault is encrypted and has not been unlocked yet
+func (v *Vault) IsLocked() bool {
+	return v.encrypted && v.key == nil
+}
+
+// Unlock derives the vault key from passphrase using the KDF parameters recorded
+// in the vault. Unlocking a plaintext vault is a no-op.
+func (v *Vault) Unlock(passphrase string) error {
+	if !v.encrypted {
+		return nil
+	}
+
+	salt, iterations, err := v.kdfParams()
+	if err != nil {
+		return err
+	}
+
+	key, err := deriveKey(passphrase, salt, iterations)
+	if err != nil {
+		return err
+	}
+
+	check, _, err := v.getMeta(metaKeyCheck)
+	if err != nil {
+		return err
+	}
+
+	value, err := openSealed(key, []byte(keyCheckAAD), check)
+	if err != nil || subtle.ConstantTimeCompare([]byte(value), []byte(keyCheckValue)) != 1 {
+		return errs.New(ErrVaultPassphrase.Code, "incorrect vault passphrase")
+	}
+
+	v.key = key
+	return nil
+}
+
+// Encrypt enables encryption at rest for a plaintext vault. A new key is derived
+// from passphrase and every stored value is re-written encrypted in a single
+// transaction. The vault is left unlocked.
+func (v *Vault) Encrypt(passphrase string) error {
+	if v.encrypted {
+		return errs.New(ErrVaultEncryption.Code, "vault is already encrypted")
+	}
+	if passphrase == "" {
+		return errs.New(ErrVaultPassphrase.Code, "vault passphrase cannot be empty")
+	}
+
+	salt := make([]byte, kdfSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return errs.Wrap(err, ErrVaultEncryption.Code, "failed to generate salt")
+	}
+
+	key, err := deriveKey(passphrase, salt, kdfIterations)
+	if err != nil {
+		return err
+	}
+
+	check, err := seal(key, []byte(keyCheckAAD), keyCheckValue)
+	if err != nil {
+		return err
+	}
+
+	tx, err := v.db.Begin()
+	if err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to begin transaction")
+	}
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	meta := map[string]string{
+		metaCipher:     cipherName,
+		metaKDF:        kdfName,
+		metaIterations: strconv.Itoa(kdfIterations),
+		metaSalt:       base64.StdEncoding.EncodeToString(salt),
+		metaKeyCheck:   check,
+	}
+	for k, value := range meta {
+		if err := setMeta(tx, k, value); err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.Query("SELECT id, collection_id, key, value FROM secrets")
+	if err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to query secrets")
+	}
+
+	type row struct {
+		id           int64
+		collectionID int64
+		key          string
+		value        string
+	}
+	var plaintext []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.collectionID, &r.key, &r.value); err != nil {
+			_ = rows.Close()
+			return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to scan secret row")
+		}
+		plaintext = append(plaintext, r)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "error iterating secret rows")
+	}
+
+	for _, r := range plaintext {
+		sealed, err := seal(key, secretAAD(r.collectionID, r.key), r.value)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("UPDATE secrets SET value = ? WHERE id = ?", sealed, r.id); err != nil {
+			return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to encrypt secret").WithContext("key", r.key)
+		}
+	}
+
+	if err := sealHistory(tx, key); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to commit encryption")
+	}
+
+	v.encrypted = true
+	v.key = key
+
+	// Rebuild the file and truncate the WAL so no plaintext is left behind in free pages
+	if _, err := v.db.Exec("VACUUM"); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to vacuum vault after encryption")
+	}
+	if _, err := v.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to checkpoint vault after encryption")
+	}
+
+	return nil
+}
+
+// sealHistory encrypts the archived versions of every secret in place
+func sealHistory(tx *sql.Tx, key []byte) error {
+	query := `
+		SELECT sv.id, s.collection_id, s.key, sv.value FROM secret_versions sv
+		JOIN secrets s ON s.id = sv.secret_id
+	`
+
+	rows, err := tx.Query(query)
+	if err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to query secret history")
+	}
+
+	type row struct {
+		id           int64
+		collectionID int64
+		key          string
+		value        string
+	}
+	var plaintext []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.collectionID, &r.key, &r.value); err != nil {
+			_ = rows.Close()
+			return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to scan secret version row")
+		}
+		plaintext = append(plaintext, r)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "error iterating secret history")
+	}
+
+	for _, r := range plaintext {
+		sealed, err := seal(key, secretAAD(r.collectionID, r.key), r.value)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("UPDATE secret_versions SET value = ? WHERE id = ?", sealed, r.id); err != nil {
+			return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to encrypt secret version").WithContext("key", r.key)
+		}
+	}
+
+	return nil
+}
+
+// sealValue prepares a secret value for storage, encrypting it when the vault is encrypted
+func (v *Vault) sealValue(collectionID int64, key, value string) (string, error) {
+	if !v.encrypted {
+		return value, nil
+	}
+	if v.key == nil {
+		return "", errs.New(ErrVaultLocked.Code, "vault is locked")
+	}
+
+	return seal(v.key, secretAAD(collectionID, key), value)
+}
+
+// openValue returns the plaintext of a stored secret value
+func (v *Vault) openValue(collectionID int64, key, stored string) (string, error) {
+	if !v.encrypted {
+		return stored, nil
+	}
+	if v.key == nil {
+		return "", errs.New(ErrVaultLocked.Code, "vault is locked")
+	}
+
+	value, err := openSealed(v.key, secretAAD(collectionID, key), stored)
+	if err != nil {
+		return "", errs.Wrap(err, ErrVaultIntegrityCheck.Code, "failed to decrypt secret").WithContext("key", key)
+	}
+
+	return value, nil
+}
+
+// loadEncryptionState reads whether the vault has been encrypted
+func (v *Vault) loadEncryptionState() error {
+	name, ok, err := v.getMeta(metaCipher)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		v.encrypted = false
+		return nil
+	}
+	if name != cipherName {
+		return errs.New(ErrVaultEncryption.Code, "unsupported vault cipher").WithContext("cipher", name)
+	}
+
+	v.encrypted = true
+	return nil
+}
+
+func (v *Vault) kdfParams() ([]byte, int, error) {
+	name, _, err := v.getMeta(metaKDF)
+	if err != nil {
+		return nil, 0, err
+	}
+	if name != kdfName {
+		return nil, 0, errs.New(ErrVaultEncryption.Code, "unsupported vault key derivation function").WithContext("kdf", name)
+	}
+
+	encodedSalt, _, err := v.getMeta(metaSalt)
+	if err != nil {
+		return nil, 0, err
+	}
+	salt, err := base64.StdEncoding.DecodeString(encodedSalt)
+	if err != nil || len(salt) == 0 {
+		return nil, 0, errs.New(ErrVaultIntegrityCheck.Code, "vault key salt is invalid")
+	}
+
+	rawIterations, _, err := v.getMeta(metaIterations)
+	if err != nil {
+		return nil, 0, err
+	}
+	iterations, err := strconv.Atoi(rawIterations)
+	if err != nil || iterations <= 0 {
+		return nil, 0, errs.New(ErrVaultIntegrityCheck.Code, "vault key iteration count is invalid").WithContext("iterations", rawIterations)
+	}
+
+	return salt, iterations, nil
+}
+
+func deriveKey(passphrase string, salt []byte, iterations int) ([]byte, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, keySize)
+	if err != nil {
+		return nil, errs.Wrap(err, ErrVaultEncryption.Code, "failed to derive vault key")
+	}
+	return key, nil
+}
+
+// secretAAD binds a sealed value to the row it belongs to so values cannot be swapped between keys
+func secretAAD(collectionID int64, key string) []byte {
+	return []byte(fmt.Sprintf("secret:%d:%s", collectionID, key))
+}
+
+func seal(key, aad []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errs.Wrap(err, ErrVaultEncryption.Code, "failed to generate nonce")
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), aad)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func openSealed(key, aad []byte, stored string) (string, error) {
+	if !strings.HasPrefix(stored, sealedPrefix) {
+		return "", errors.New("value is not encrypted")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, sealedPrefix))
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("encrypted value is truncated")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errs.Wrap(err, ErrVaultEncryption.Code, "failed to create cipher")
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errs.Wrap(err, ErrVaultEncryption.Code, "failed to create cipher")
+	}
+
+	return gcm, nil
+}
@@ -0,0 +1,184 @@
+package vault
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+const testPassphrase = "correct horse battery staple"
+
+// createTestVault creates a vault in a temporary directory, encrypted when a
+// passphrase is given, and closes it when the test finishes
+func createTestVault(t *testing.T, passphrase string) (*Vault, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	v, err := Create(path, CreateOptions{Passphrase: passphrase})
+	errs.AssertNoError(t, err)
+	t.Cleanup(func() {
+		_ = v.Close()
+	})
+
+	return v, path
+}
+
+// storedValues returns every raw value column of a table
+func storedValues(t *testing.T, v *Vault, table string) []string {
+	t.Helper()
+
+	rows, err := v.db.Query("SELECT value FROM " + table)
+	errs.AssertNoError(t, err)
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		errs.AssertNoError(t, rows.Scan(&value))
+		values = append(values, value)
+	}
+	errs.AssertNoError(t, rows.Err())
+
+	return values
+}
+
+func TestSealValueRoundTrip(t *testing.T) {
+	v, _ := createTestVault(t, testPassphrase)
+
+	collectionID, err := v.collectionID("global")
+	errs.AssertNoError(t, err)
+
+	sealed, err := v.sealValue(collectionID, "API_KEY", "s3cret")
+	errs.AssertNoError(t, err)
+	if !strings.HasPrefix(sealed, sealedPrefix) || strings.Contains(sealed, "s3cret") {
+		t.Fatalf("sealValue() = %q, want an %s ciphertext", sealed, sealedPrefix)
+	}
+
+	value, err := v.openValue(collectionID, "API_KEY", sealed)
+	errs.AssertNoError(t, err)
+	if value != "s3cret" {
+		t.Errorf("openValue() = %q, want %q", value, "s3cret")
+	}
+
+	tests := []struct {
+		name         string
+		collectionID int64
+		key          string
+	}{
+		{name: "other key", collectionID: collectionID, key: "OTHER_KEY"},
+		{name: "other collection", collectionID: collectionID + 1, key: "API_KEY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.openValue(tt.collectionID, tt.key, sealed)
+			errs.AssertErrorCode(t, err, ErrVaultIntegrityCheck.Code)
+		})
+	}
+}
+
+func TestMovedCiphertextFailsToOpen(t *testing.T) {
+	v, _ := createTestVault(t, testPassphrase)
+
+	errs.AssertNoError(t, v.SetSecret("global", "A", "value-a", false))
+	errs.AssertNoError(t, v.SetSecret("global", "B", "value-b", false))
+
+	_, err := v.db.Exec("UPDATE secrets SET value = (SELECT value FROM secrets WHERE key = 'A') WHERE key = 'B'")
+	errs.AssertNoError(t, err)
+
+	_, err = v.GetSecret("global", "B")
+	errs.AssertErrorCode(t, err, ErrVaultIntegrityCheck.Code)
+
+	secret, err := v.GetSecret("global", "A")
+	errs.AssertNoError(t, err)
+	if secret.Value != "value-a" {
+		t.Errorf("GetSecret() = %q, want %q", secret.Value, "value-a")
+	}
+}
+
+func TestUnlockWrongPassphrase(t *testing.T) {
+	v, path := createTestVault(t, testPassphrase)
+	errs.AssertNoError(t, v.SetSecret("global", "A", "value-a", false))
+	errs.AssertNoError(t, v.Close())
+
+	v, err := Open(NewPathDatasource(path))
+	errs.AssertNoError(t, err)
+	defer func() {
+		_ = v.Close()
+	}()
+
+	if !v.IsLocked() {
+		t.Fatal("IsLocked() = false after reopening an encrypted vault")
+	}
+
+	err = v.Unlock("wrong passphrase")
+	errs.AssertErrorCode(t, err, ErrVaultPassphrase.Code)
+	if !v.IsLocked() {
+		t.Error("IsLocked() = false after a failed unlock")
+	}
+
+	_, err = v.GetSecret("global", "A")
+	errs.AssertErrorCode(t, err, ErrVaultLocked.Code)
+
+	errs.AssertNoError(t, v.Unlock(testPassphrase))
+	secret, err := v.GetSecret("global", "A")
+	errs.AssertNoError(t, err)
+	if secret.Value != "value-a" {
+		t.Errorf("GetSecret() = %q, want %q", secret.Value, "value-a")
+	}
+}
+
+func TestEncryptMigratesPlaintext(t *testing.T) {
+	v, path := createTestVault(t, "")
+
+	errs.AssertNoError(t, v.SetSecret("global", "A", "value-a1", false))
+	errs.AssertNoError(t, v.SetSecret("global", "A", "value-a2", true))
+	errs.AssertNoError(t, v.SetSecret("global", "B", "value-b", false))
+
+	errs.AssertNoError(t, v.Encrypt(testPassphrase))
+
+	for _, table := range []string{"secrets", "secret_versions"} {
+		values := storedValues(t, v, table)
+		if len(values) == 0 {
+			t.Fatalf("no rows in %s", table)
+		}
+		for _, value := range values {
+			if !strings.HasPrefix(value, sealedPrefix) {
+				t.Errorf("%s value %q was not encrypted", table, value)
+			}
+		}
+	}
+
+	err := v.Encrypt(testPassphrase)
+	errs.AssertErrorCode(t, err, ErrVaultEncryption.Code)
+	errs.AssertNoError(t, v.Close())
+
+	v, err = Open(NewPathDatasource(path))
+	errs.AssertNoError(t, err)
+	defer func() {
+		_ = v.Close()
+	}()
+	errs.AssertNoError(t, v.Unlock(testPassphrase))
+
+	tests := []struct {
+		key     string
+		version int
+		want    string
+	}{
+		{key: "A", version: 1, want: "value-a1"},
+		{key: "A", version: 2, want: "value-a2"},
+		{key: "B", version: 1, want: "value-b"},
+	}
+
+	for _, tt := range tests {
+		secret, err := v.GetSecretVersion("global", tt.key, tt.version)
+		errs.AssertNoError(t, err)
+		if secret.Value != tt.want {
+			t.Errorf("GetSecretVersion(%s, %d) = %q, want %q", tt.key, tt.version, secret.Value, tt.want)
+		}
+	}
+}
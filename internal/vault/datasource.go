@@ -104,6 +104,29 @@ func (f *filesystemDatasource) Datasource() (Datasource, error) {
 	return f.datasource, nil
 }
 
+// pathDatasource provides the datasource for a vault at an explicit path
+type pathDatasource struct {
+	path string
+}
+
+// NewPathDatasource returns a provider for the vault file at path. Unlike
+// NewFileSystemDatasource nothing is created; Datasource fails if path is not a vault.
+func NewPathDatasource(path string) DatasourceProvider {
+	return &pathDatasource{path: path}
+}
+
+func (p *pathDatasource) Datasource() (Datasource, error) {
+	isVault, err := IsVault(p.path)
+	if err != nil {
+		return "", err
+	}
+	if !isVault {
+		return "", errs.New(ErrDatasourceUnreachable.Code, "no valid vault found at path").WithContext("path", p.path)
+	}
+
+	return Datasource(p.path), nil
+}
+
 func updateFilesystemDatasourcePath(f *filesystemDatasource) error {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -9,6 +9,20 @@ var (
 	ErrVaultConnectionFailed = errs.New(error_codes.VaultConnectionErrCode, "failed to connect to vault")
 	ErrVaultIntegrityCheck   = errs.New(error_codes.VaultIntegrityErrCode, "vault integrity check failed")
 	ErrVaultCreationFailed   = errs.New(error_codes.VaultCreationErrCode, "failed to create vault")
+	ErrVaultExists           = errs.New(error_codes.VaultExistsErrCode, "vault already exists")
 	ErrDatasourcePathInvalid = errs.New(error_codes.DatasourceErrCode, "invalid datasource path")
 	ErrDatasourceUnreachable = errs.New(error_codes.DatasourceErrCode, "datasource database unreachable")
+
+	ErrSecretNotFound     = errs.New(error_codes.SecretNotFoundErrCode, "secret not found")
+	ErrSecretExists       = errs.New(error_codes.SecretExistsErrCode, "secret already exists")
+	ErrSecretInvalid      = errs.New(error_codes.SecretInvalidErrCode, "invalid secret")
+	ErrCollectionNotFound = errs.New(error_codes.CollectionNotFoundErrCode, "collection not found")
+	ErrCollectionExists   = errs.New(error_codes.CollectionExistsErrCode, "collection already exists")
+	ErrCollectionNotEmpty = errs.New(error_codes.CollectionNotEmptyErrCode, "collection is not empty")
+	ErrCollectionInvalid  = errs.New(error_codes.CollectionInvalidErrCode, "invalid collection")
+	ErrVaultQueryFailed   = errs.New(error_codes.DatabaseFailureErrCode, "vault query failed")
+
+	ErrVaultLocked     = errs.New(error_codes.VaultLockedErrCode, "vault is locked")
+	ErrVaultPassphrase = errs.New(error_codes.VaultPassphraseErrCode, "invalid vault passphrase")
+	ErrVaultEncryption = errs.New(error_codes.VaultEncryptionErrCode, "vault encryption failed")
 )
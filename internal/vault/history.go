@@ -0,0 +1,233 @@
+package vault
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// SecretVersion describes one version of a secret. Version numbers start at 1
+// and increase with every write.
+type SecretVersion struct {
+	Version   int    `json:"version"`
+	CreatedAt string `json:"created_at"`
+	Current   bool   `json:"current"`
+}
+
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// SecretHistory returns every version of a secret, oldest first. Values are not
+// included so the vault does not need to be unlocked.
+func (v *Vault) SecretHistory(collection, key string) ([]SecretVersion, error) {
+	collectionID, err := v.collectionID(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT id, version, updated_at FROM secrets WHERE collection_id = ? AND key = ?"
+
+	var (
+		id      int64
+		current SecretVersion
+	)
+	err = v.db.QueryRow(query, collectionID, key).Scan(&id, &current.Version, &current.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.New(ErrSecretNotFound.Code, "secret not found").
+				WithContext("collection", collection).
+				WithContext("key", key)
+		}
+		return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to get secret").
+			WithContext("collection", collection).
+			WithContext("key", key)
+	}
+	current.Current = true
+
+	rows, err := v.db.Query("SELECT version, created_at FROM secret_versions WHERE secret_id = ? ORDER BY version", id)
+	if err != nil {
+		return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to query secret history").
+			WithContext("collection", collection).
+			WithContext("key", key)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var history []SecretVersion
+	for rows.Next() {
+		var version SecretVersion
+		if err := rows.Scan(&version.Version, &version.CreatedAt); err != nil {
+			return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to scan secret version")
+		}
+		history = append(history, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "error iterating secret history")
+	}
+
+	return append(history, current), nil
+}
+
+// GetSecretVersion returns the secret as it was at the given version
+func (v *Vault) GetSecretVersion(collection, key string, version int) (*Secret, error) {
+	secret, err := v.GetSecret(collection, key)
+	if err != nil {
+		return nil, err
+	}
+	if secret.Version == version {
+		return secret, nil
+	}
+
+	collectionID, err := v.collectionID(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT sv.value, sv.created_at FROM secret_versions sv
+		JOIN secrets s ON s.id = sv.secret_id
+		WHERE s.collection_id = ? AND s.key = ? AND sv.version = ?
+	`
+
+	var stored string
+	err = v.db.QueryRow(query, collectionID, key, version).Scan(&stored, &secret.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.New(ErrSecretNotFound.Code, "secret version not found").
+				WithContext("collection", collection).
+				WithContext("key", key).
+				WithContext("version", version)
+		}
+		return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to get secret version").
+			WithContext("collection", collection).
+			WithContext("key", key).
+			WithContext("version", version)
+	}
+
+	secret.Value, err = v.openValue(collectionID, key, stored)
+	if err != nil {
+		return nil, err
+	}
+	secret.Version = version
+
+	return secret, nil
+}
+
+// HasSecretVersion reports whether the given version of a secret exists. It
+// does not need the vault to be unlocked.
+func (v *Vault) HasSecretVersion(collection, key string, version int) (bool, error) {
+	history, err := v.SecretHistory(collection, key)
+	if err != nil {
+		if errs.Is(err, ErrSecretNotFound.Code) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, h := range history {
+		if h.Version == version {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// RollbackSecret restores the value a secret had at version. The rollback is
+// written as a new version so it can itself be undone. The new version number
+// is returned. Stored values are copied as they are, so an encrypted vault does
+// not need to be unlocked.
+func (v *Vault) RollbackSecret(collection, key string, version int) (int, error) {
+	collectionID, err := v.collectionID(collection)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := v.db.Begin()
+	if err != nil {
+		return 0, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to begin transaction")
+	}
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	id, current, err := secretRow(tx, collectionID, collection, key)
+	if err != nil {
+		return 0, err
+	}
+	if version == current {
+		return 0, errs.New(ErrSecretInvalid.Code, "secret is already at this version").
+			WithContext("key", key).
+			WithContext("version", version)
+	}
+
+	var stored string
+	err = tx.QueryRow("SELECT value FROM secret_versions WHERE secret_id = ? AND version = ?", id, version).Scan(&stored)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errs.New(ErrSecretNotFound.Code, "secret version not found").
+				WithContext("collection", collection).
+				WithContext("key", key).
+				WithContext("version", version)
+		}
+		return 0, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to get secret version").
+			WithContext("key", key).
+			WithContext("version", version)
+	}
+
+	if err := replaceValue(tx, id, stored); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to commit rollback").
+			WithContext("key", key)
+	}
+
+	return current + 1, nil
+}
+
+// secretRow returns the id and current version of a secret
+func secretRow(q queryer, collectionID int64, collection, key string) (int64, int, error) {
+	var (
+		id      int64
+		version int
+	)
+
+	err := q.QueryRow("SELECT id, version FROM secrets WHERE collection_id = ? AND key = ?", collectionID, key).Scan(&id, &version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, 0, errs.New(ErrSecretNotFound.Code, "secret not found").
+				WithContext("collection", collection).
+				WithContext("key", key)
+		}
+		return 0, 0, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to get secret").
+			WithContext("collection", collection).
+			WithContext("key", key)
+	}
+
+	return id, version, nil
+}
+
+// replaceValue moves the current value of secret id into its history and stores
+// sealed as the next version
+func replaceValue(tx *sql.Tx, id int64, sealed string) error {
+	archive := `
+		INSERT INTO secret_versions (secret_id, version, value, created_at)
+		SELECT id, version, value, updated_at FROM secrets WHERE id = ?
+	`
+	if _, err := tx.Exec(archive, id); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to archive secret version")
+	}
+
+	update := "UPDATE secrets SET value = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	if _, err := tx.Exec(update, sealed, id); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to update secret")
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package vault
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// metaID is the vault_meta key holding the vault's stable identifier
+const metaID = "vault_id"
+
+// ID returns the identifier assigned to the vault when it was created, or when
+// it was first opened by a version of knox that assigns identifiers. The
+// identifier survives the vault file being moved or renamed.
+func (v *Vault) ID() (string, error) {
+	id, _, err := v.getMeta(metaID)
+	return id, err
+}
+
+// ReadID returns the identifier of the vault at path without migrating it. It
+// returns an empty string for vaults that have not been assigned one yet.
+func ReadID(path string) (string, error) {
+	isVault, err := IsVault(path)
+	if err != nil {
+		return "", err
+	}
+	if !isVault {
+		return "", errs.New(ErrDatasourceUnreachable.Code, "no valid vault found at path").WithContext("path", path)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return "", errs.Wrap(err, ErrVaultConnectionFailed.Code, "failed to open database").WithContext("path", path)
+	}
+	defer func(db *sql.DB) {
+		_ = db.Close()
+	}(db)
+
+	var tables int
+	err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name = 'vault_meta'").Scan(&tables)
+	if err != nil {
+		return "", errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to check vault metadata table").WithContext("path", path)
+	}
+	if tables == 0 {
+		return "", nil
+	}
+
+	var id string
+	err = db.QueryRow("SELECT value FROM vault_meta WHERE key = ?", metaID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to read vault id").WithContext("path", path)
+	}
+
+	return id, nil
+}
+
+// FindByID searches dir for a vault file carrying id and returns its path, or
+// an empty string when no vault in dir matches. Files that are not vaults are
+// ignored.
+func FindByID(dir, id string) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", errs.Wrap(err, ErrDatasourcePathInvalid.Code, "failed to read vaults directory").WithContext("path", dir)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".db" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		candidate, err := ReadID(path)
+		if err != nil {
+			continue
+		}
+		if candidate == id {
+			return path, nil
+		}
+	}
+
+	return "", nil
+}
+
+// assignID records a new identifier unless the vault already has one
+func assignID(tx *sql.Tx) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT OR IGNORE INTO vault_meta (key, value) VALUES (?, ?)", metaID, id)
+	return err
+}
+
+// newID returns a random version 4 UUID
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", errs.Wrap(err, ErrVaultCreationFailed.Code, "failed to generate vault id")
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,46 @@
+package vault
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+const vaultMetaSchema = `
+CREATE TABLE IF NOT EXISTS vault_meta (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// getMeta returns the metadata value stored under key and whether it exists
+func (v *Vault) getMeta(key string) (string, bool, error) {
+	var value string
+	err := v.db.QueryRow("SELECT value FROM vault_meta WHERE key = ?", key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to get vault metadata").WithContext("key", key)
+	}
+
+	return value, true, nil
+}
+
+// setMeta stores a metadata value under key
+func setMeta(db execer, key, value string) error {
+	query := `
+		INSERT INTO vault_meta (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`
+
+	if _, err := db.Exec(query, key, value); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to set vault metadata").WithContext("key", key)
+	}
+	return nil
+}
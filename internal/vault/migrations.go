@@ -0,0 +1,80 @@
+package vault
+
+import (
+	"database/sql"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/tomdoesdev/knox/kit/migrate"
+)
+
+// migrations upgrade a vault schema in order. Vaults created before versioning
+// report user_version 0, so early migrations must be safe to run against a
+// schema that already has their tables.
+var migrations = migrate.New(
+	migrate.Migration{
+		Version:     1,
+		Description: "create secrets and collections tables",
+		Up: migrate.SQL(vaultTableSchema + `
+			INSERT OR IGNORE INTO collections (name, description) VALUES ('global', 'Default collection for vault');
+		`),
+	},
+	migrate.Migration{
+		Version:     2,
+		Description: "create vault_meta table",
+		Up:          migrate.SQL(vaultMetaSchema),
+	},
+	migrate.Migration{
+		Version:     3,
+		Description: "add secret versions and history table",
+		Up: migrate.SQL(`
+			ALTER TABLE secrets ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+			CREATE TABLE secret_versions (
+				id INTEGER PRIMARY KEY,
+				secret_id INTEGER NOT NULL,
+				version INTEGER NOT NULL,
+				value TEXT NOT NULL,
+				created_at TEXT NOT NULL,
+
+				UNIQUE (secret_id, version),
+				FOREIGN KEY (secret_id) REFERENCES secrets(id) ON DELETE CASCADE
+			);
+		`),
+	},
+	migrate.Migration{
+		Version:     4,
+		Description: "assign vault id",
+		Up:          assignID,
+	},
+)
+
+// SchemaStatus reports the schema version of the vault at path and any pending
+// migrations without applying them
+func SchemaStatus(path string) (migrate.Status, error) {
+	isVault, err := IsVault(path)
+	if err != nil {
+		return migrate.Status{}, err
+	}
+	if !isVault {
+		return migrate.Status{}, errs.New(ErrDatasourceUnreachable.Code, "no valid vault found at path").WithContext("path", path)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return migrate.Status{}, errs.Wrap(err, ErrVaultConnectionFailed.Code, "failed to open database").WithContext("path", path)
+	}
+	defer func(db *sql.DB) {
+		_ = db.Close()
+	}(db)
+
+	return migrations.Status(db)
+}
+
+// migrate applies pending schema migrations to an open vault
+func (v *Vault) migrate() error {
+	if _, err := migrations.Up(v.db); err != nil {
+		return errs.Wrap(err, ErrVaultIntegrityCheck.Code, "failed to migrate vault schema").
+			WithContext("datasource", v.datasource.String())
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// VaultsDirName is the directory under the knox root holding vaults created by alias
+const VaultsDirName = "vaults"
+
+// DefaultVaultPath returns where a vault created under alias is stored when no
+// path is given: $KNOX_ROOT/vaults/<alias>.db, or ~/.knox/vaults/<alias>.db
+func DefaultVaultPath(alias string) (string, error) {
+	if err := ValidateAlias(alias); err != nil {
+		return "", err
+	}
+
+	dir, err := DefaultVaultsDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, alias+".db"), nil
+}
+
+// DefaultVaultsDir returns the directory holding vaults created by alias:
+// $KNOX_ROOT/vaults, or ~/.knox/vaults
+func DefaultVaultsDir() (string, error) {
+	root, err := RootDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(root, VaultsDirName), nil
+}
+
+// RootDir returns the knox root directory: $KNOX_ROOT, or ~/.knox
+func RootDir() (string, error) {
+	if root := os.Getenv(KnoxRootEnvVar); root != "" {
+		return root, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errs.Wrap(err, ErrDatasourcePathInvalid.Code, "failed to get user home directory")
+	}
+
+	return filepath.Join(home, DefaultDirName), nil
+}
+
+// ValidateAlias checks that alias can be used in key@vault/collection
+// references and as a file name under the vaults directory
+func ValidateAlias(alias string) error {
+	if strings.TrimSpace(alias) == "" {
+		return errs.New(error_codes.ValidationErrCode, "vault alias cannot be empty")
+	}
+	if strings.ContainsAny(alias, "@/\\# \t\n") || strings.Contains(alias, "..") {
+		return errs.New(error_codes.ValidationErrCode, "vault alias cannot contain '@', '/', '\\', '#', '..' or whitespace").WithContext("alias", alias)
+	}
+
+	return nil
+}
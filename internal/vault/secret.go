@@ -0,0 +1,282 @@
+package vault
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// Secret is a single key/value entry stored in a vault collection
+type Secret struct {
+	Collection string `json:"collection"`
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+	Version    int    `json:"version"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// GetSecret returns the secret stored under key in the given collection
+func (v *Vault) GetSecret(collection, key string) (*Secret, error) {
+	collectionID, err := v.collectionID(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT key, value, version, created_at, updated_at FROM secrets WHERE collection_id = ? AND key = ?"
+
+	secret := Secret{Collection: collection}
+	err = v.db.QueryRow(query, collectionID, key).Scan(&secret.Key, &secret.Value, &secret.Version, &secret.CreatedAt, &secret.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.New(ErrSecretNotFound.Code, "secret not found").
+				WithContext("collection", collection).
+				WithContext("key", key)
+		}
+		return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to get secret").
+			WithContext("collection", collection).
+			WithContext("key", key)
+	}
+
+	secret.Value, err = v.openValue(collectionID, secret.Key, secret.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &secret, nil
+}
+
+// HasSecret reports whether key exists in the given collection. It does not
+// need the vault to be unlocked.
+func (v *Vault) HasSecret(collection, key string) (bool, error) {
+	collectionID, err := v.collectionID(collection)
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = v.db.QueryRow("SELECT EXISTS(SELECT 1 FROM secrets WHERE collection_id = ? AND key = ?)", collectionID, key).Scan(&exists)
+	if err != nil {
+		return false, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to check secret").
+			WithContext("collection", collection).
+			WithContext("key", key)
+	}
+
+	return exists, nil
+}
+
+// SetSecret stores value under key in the given collection. If the key already
+// exists an ErrSecretExists error is returned unless overwrite is true, in
+// which case the previous value is kept in the secret's history.
+func (v *Vault) SetSecret(collection, key, value string, overwrite bool) error {
+	if err := validateSecretKey(key); err != nil {
+		return err
+	}
+
+	collectionID, err := v.collectionID(collection)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := v.sealValue(collectionID, key, value)
+	if err != nil {
+		return err
+	}
+
+	tx, err := v.db.Begin()
+	if err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to begin transaction")
+	}
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	id, _, err := secretRow(tx, collectionID, collection, key)
+	switch {
+	case errs.Is(err, ErrSecretNotFound.Code):
+		_, err = tx.Exec("INSERT INTO secrets (collection_id, key, value) VALUES (?, ?, ?)", collectionID, key, sealed)
+		if err != nil {
+			if isUniqueConstraintErr(err) {
+				return errs.New(ErrSecretExists.Code, "secret already exists").
+					WithContext("collection", collection).
+					WithContext("key", key)
+			}
+			return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to set secret").
+				WithContext("collection", collection).
+				WithContext("key", key)
+		}
+	case err != nil:
+		return err
+	case !overwrite:
+		return errs.New(ErrSecretExists.Code, "secret already exists").
+			WithContext("collection", collection).
+			WithContext("key", key)
+	default:
+		if err := replaceValue(tx, id, sealed); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to commit secret").
+			WithContext("collection", collection).
+			WithContext("key", key)
+	}
+
+	return nil
+}
+
+// DeleteSecret removes the secret stored under key in the given collection
+// together with its history
+func (v *Vault) DeleteSecret(collection, key string) error {
+	collectionID, err := v.collectionID(collection)
+	if err != nil {
+		return err
+	}
+
+	tx, err := v.db.Begin()
+	if err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to begin transaction")
+	}
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	id, _, err := secretRow(tx, collectionID, collection, key)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM secret_versions WHERE secret_id = ?", id); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to delete secret history").
+			WithContext("collection", collection).
+			WithContext("key", key)
+	}
+
+	if _, err := tx.Exec("DELETE FROM secrets WHERE id = ?", id); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to delete secret").
+			WithContext("collection", collection).
+			WithContext("key", key)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to commit secret deletion").
+			WithContext("collection", collection).
+			WithContext("key", key)
+	}
+
+	return nil
+}
+
+// ListSecrets returns all secrets in the given collection ordered by key
+func (v *Vault) ListSecrets(collection string) ([]Secret, error) {
+	collectionID, err := v.collectionID(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT key, value, version, created_at, updated_at FROM secrets WHERE collection_id = ? ORDER BY key"
+
+	rows, err := v.db.Query(query, collectionID)
+	if err != nil {
+		return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to query secrets").
+			WithContext("collection", collection)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var secrets []Secret
+	for rows.Next() {
+		secret := Secret{Collection: collection}
+		err := rows.Scan(&secret.Key, &secret.Value, &secret.Version, &secret.CreatedAt, &secret.UpdatedAt)
+		if err != nil {
+			return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to scan secret row")
+		}
+
+		secret.Value, err = v.openValue(collectionID, secret.Key, secret.Value)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "error iterating secret rows")
+	}
+
+	return secrets, nil
+}
+
+// ListSecretKeys returns the keys of all secrets in the given collection ordered
+// by key. Unlike ListSecrets it does not need the vault to be unlocked.
+func (v *Vault) ListSecretKeys(collection string) ([]string, error) {
+	collectionID, err := v.collectionID(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := v.db.Query("SELECT key FROM secrets WHERE collection_id = ? ORDER BY key", collectionID)
+	if err != nil {
+		return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to query secret keys").
+			WithContext("collection", collection)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to scan secret key")
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errs.Wrap(err, ErrVaultQueryFailed.Code, "error iterating secret keys")
+	}
+
+	return keys, nil
+}
+
+// collectionID looks up the id of a collection by name
+func (v *Vault) collectionID(name string) (int64, error) {
+	var id int64
+	err := v.db.QueryRow("SELECT id FROM collections WHERE name = ?", name).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errs.New(ErrCollectionNotFound.Code, "collection not found").WithContext("collection", name)
+		}
+		return 0, errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to look up collection").WithContext("collection", name)
+	}
+
+	return id, nil
+}
+
+// validateSecretKey rejects keys that would make secret references ambiguous
+func validateSecretKey(key string) error {
+	if strings.TrimSpace(key) == "" {
+		return errs.New(ErrSecretInvalid.Code, "secret key cannot be empty")
+	}
+	if key != strings.TrimSpace(key) {
+		return errs.New(ErrSecretInvalid.Code, "secret key cannot start or end with whitespace").WithContext("key", key)
+	}
+	if strings.ContainsAny(key, "@/#") {
+		return errs.New(ErrSecretInvalid.Code, "secret key cannot contain '@', '/' or '#'").WithContext("key", key)
+	}
+
+	return nil
+}
+
+func isUniqueConstraintErr(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package vault
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+func TestSecretCRUD(t *testing.T) {
+	v, _ := createTestVault(t, testPassphrase)
+
+	errs.AssertNoError(t, v.SetSecret("global", "API_KEY", "v1", false))
+	errs.AssertNoError(t, v.SetSecret("global", "DB_URL", "postgres://db", false))
+
+	secret, err := v.GetSecret("global", "API_KEY")
+	errs.AssertNoError(t, err)
+	if secret.Value != "v1" || secret.Version != 1 || secret.Collection != "global" {
+		t.Errorf("GetSecret() = %+v, want value v1 at version 1 in global", secret)
+	}
+
+	exists, err := v.HasSecret("global", "API_KEY")
+	errs.AssertNoError(t, err)
+	if !exists {
+		t.Error("HasSecret() = false for a stored secret")
+	}
+
+	err = v.SetSecret("global", "API_KEY", "v2", false)
+	errs.AssertErrorCode(t, err, ErrSecretExists.Code)
+
+	errs.AssertNoError(t, v.SetSecret("global", "API_KEY", "v2", true))
+	secret, err = v.GetSecret("global", "API_KEY")
+	errs.AssertNoError(t, err)
+	if secret.Value != "v2" || secret.Version != 2 {
+		t.Errorf("GetSecret() after update = %q at version %d, want v2 at version 2", secret.Value, secret.Version)
+	}
+
+	keys, err := v.ListSecretKeys("global")
+	errs.AssertNoError(t, err)
+	if !reflect.DeepEqual(keys, []string{"API_KEY", "DB_URL"}) {
+		t.Errorf("ListSecretKeys() = %v, want [API_KEY DB_URL]", keys)
+	}
+
+	secrets, err := v.ListSecrets("global")
+	errs.AssertNoError(t, err)
+	if len(secrets) != 2 || secrets[0].Value != "v2" || secrets[1].Value != "postgres://db" {
+		t.Errorf("ListSecrets() = %+v, want API_KEY=v2 and DB_URL=postgres://db", secrets)
+	}
+
+	errs.AssertNoError(t, v.DeleteSecret("global", "API_KEY"))
+
+	_, err = v.GetSecret("global", "API_KEY")
+	errs.AssertErrorCode(t, err, ErrSecretNotFound.Code)
+
+	err = v.DeleteSecret("global", "API_KEY")
+	errs.AssertErrorCode(t, err, ErrSecretNotFound.Code)
+
+	_, err = v.GetSecretVersion("global", "API_KEY", 1)
+	errs.AssertErrorCode(t, err, ErrSecretNotFound.Code)
+
+	// A deleted key starts a new history
+	errs.AssertNoError(t, v.SetSecret("global", "API_KEY", "v3", false))
+	secret, err = v.GetSecret("global", "API_KEY")
+	errs.AssertNoError(t, err)
+	if secret.Value != "v3" || secret.Version != 1 {
+		t.Errorf("GetSecret() after re-creating = %q at version %d, want v3 at version 1", secret.Value, secret.Version)
+	}
+}
+
+func TestSecretMissingCollection(t *testing.T) {
+	v, _ := createTestVault(t, "")
+
+	_, err := v.GetSecret("missing", "A")
+	errs.AssertErrorCode(t, err, ErrCollectionNotFound.Code)
+
+	err = v.SetSecret("missing", "A", "value", false)
+	errs.AssertErrorCode(t, err, ErrCollectionNotFound.Code)
+
+	err = v.DeleteSecret("missing", "A")
+	errs.AssertErrorCode(t, err, ErrCollectionNotFound.Code)
+}
+
+func TestSetSecretKeyValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		valid bool
+	}{
+		{name: "simple", key: "API_KEY", valid: true},
+		{name: "dots and dashes", key: "api.key-2", valid: true},
+		{name: "inner space", key: "API KEY", valid: true},
+		{name: "empty", key: ""},
+		{name: "whitespace only", key: "   "},
+		{name: "leading space", key: " API_KEY"},
+		{name: "trailing newline", key: "API_KEY\n"},
+		{name: "at sign", key: "API@KEY"},
+		{name: "slash", key: "API/KEY"},
+		{name: "hash", key: "API_KEY#2"},
+	}
+
+	v, _ := createTestVault(t, "")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.SetSecret("global", tt.key, "value", false)
+			if tt.valid {
+				errs.AssertNoError(t, err)
+				return
+			}
+
+			errs.AssertErrorCode(t, err, ErrSecretInvalid.Code)
+			exists, err := v.HasSecret("global", tt.key)
+			errs.AssertNoError(t, err)
+			if exists {
+				t.Errorf("invalid key %q was stored", tt.key)
+			}
+		})
+	}
+}
@@ -51,18 +51,11 @@ func createSqliteFile(dsp string) error {
 			WithContext("path", dsp)
 	}
 
-	if _, err := db.Exec(vaultTableSchema); err != nil {
+	if _, err := migrations.Up(db); err != nil {
 		return errs.Wrap(err, ErrVaultCreationFailed.Code, "failed to create database schema").
 			WithContext("path", dsp)
 	}
 
-	// Create default 'global' collection
-	_, err = db.Exec("INSERT INTO collections (name, description) VALUES ('global', 'Default collection for vault')")
-	if err != nil {
-		return errs.Wrap(err, ErrVaultCreationFailed.Code, "failed to create default global collection").
-			WithContext("path", dsp)
-	}
-
 	return nil
 }
 
@@ -0,0 +1,65 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// ReadState returns a digest of the collections and stored secret values of
+// the vault at path without migrating it. Any change to a collection or a
+// secret changes the digest, so comparing two digests tells whether the vault
+// was modified in between. Encrypted vaults do not need to be unlocked.
+func ReadState(path string) (string, error) {
+	isVault, err := IsVault(path)
+	if err != nil {
+		return "", err
+	}
+	if !isVault {
+		return "", errs.New(ErrDatasourceUnreachable.Code, "no valid vault found at path").WithContext("path", path)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return "", errs.Wrap(err, ErrVaultConnectionFailed.Code, "failed to open database").WithContext("path", path)
+	}
+	defer func(db *sql.DB) {
+		_ = db.Close()
+	}(db)
+
+	query := `
+		SELECT c.name, COALESCE(c.description, ''), COALESCE(s.key, ''), COALESCE(s.version, 0), COALESCE(s.value, '')
+		FROM collections c
+		LEFT JOIN secrets s ON s.collection_id = c.id
+		ORDER BY c.name, s.key
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return "", errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to read vault state").WithContext("path", path)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	h := sha256.New()
+	for rows.Next() {
+		var collection, description, key, value string
+		var version int
+		if err := rows.Scan(&collection, &description, &key, &version, &value); err != nil {
+			return "", errs.Wrap(err, ErrVaultQueryFailed.Code, "failed to scan vault state").WithContext("path", path)
+		}
+
+		// Length prefixes keep different rows from producing the same input
+		_, _ = fmt.Fprintf(h, "%d:%s%d:%s%d:%s%d;%d:%s",
+			len(collection), collection, len(description), description, len(key), key, version, len(value), value)
+	}
+	if err := rows.Err(); err != nil {
+		return "", errs.Wrap(err, ErrVaultQueryFailed.Code, "error iterating vault state").WithContext("path", path)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
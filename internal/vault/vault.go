@@ -10,9 +10,13 @@ import (
 type Vault struct {
 	datasource Datasource
 	db         *sql.DB
+	encrypted  bool
+	key        []byte
 }
 
 func (v *Vault) Close() error {
+	clear(v.key)
+	v.key = nil
 	return v.db.Close()
 }
 
@@ -34,8 +38,20 @@ func Open(dsp DatasourceProvider) (*Vault, error) {
 			WithContext("datasource", datasource.String())
 	}
 
-	return &Vault{
+	v := &Vault{
 		datasource: datasource,
 		db:         db,
-	}, nil
+	}
+
+	if err := v.migrate(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if err := v.loadEncryptionState(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return v, nil
 }
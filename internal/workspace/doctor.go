@@ -0,0 +1,480 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace/internal"
+	"github.com/tomdoesdev/knox/internal/workspace/internal/database"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/tomdoesdev/knox/kit/fs"
+	"github.com/tomdoesdev/knox/kit/migrate"
+)
+
+// Severity of a doctor finding
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Finding is a problem found by Diagnose, or a note on how knox resolved its
+// environment
+type Finding struct {
+	// Subject names what was checked, such as "workspace database" or "vault 'v1'"
+	Subject  string
+	Severity Severity
+	Problem  string
+	// Suggestion tells the user how to fix the problem
+	Suggestion string
+	// Fixed is set by Repair once the problem is fixed, FixErr when fixing failed
+	Fixed  bool
+	FixErr error
+	repair func() error
+}
+
+// CanFix reports whether Repair is able to fix the finding
+func (f *Finding) CanFix() bool {
+	return f.repair != nil
+}
+
+// Resolved reports whether the finding no longer needs attention
+func (f *Finding) Resolved() bool {
+	return f.Fixed || f.Severity == SeverityInfo
+}
+
+// Diagnose checks the knox environment and the workspace in dir without
+// changing anything: the workspace database, every project file and every
+// linked vault. An empty dir means no workspace was found. Findings are
+// ordered by severity, errors first, and keep the order they were found in
+// within each severity.
+func Diagnose(dir string) []*Finding {
+	d := &diagnosis{dir: dir}
+
+	d.checkEnvironment()
+
+	if dir == "" {
+		d.add(&Finding{
+			Subject:    "workspace",
+			Severity:   SeverityError,
+			Problem:    "no workspace found in the current directory or its parents",
+			Suggestion: "run 'knox init' to create one",
+		})
+	} else {
+		linked := d.checkDatabase()
+		d.checkProjects(linked)
+		d.checkVaults(linked)
+	}
+
+	sort.SliceStable(d.findings, func(i, j int) bool {
+		return d.findings[i].Severity.rank() < d.findings[j].Severity.rank()
+	})
+
+	return d.findings
+}
+
+// rank orders severities from most to least severe
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 0
+	case SeverityWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Repair applies the fix of every finding that has one, recording the outcome
+// on the finding. Fixes run in the order the findings were reported.
+func Repair(findings []*Finding) {
+	for _, f := range findings {
+		if f.repair == nil || f.Fixed {
+			continue
+		}
+
+		if err := f.repair(); err != nil {
+			f.FixErr = err
+			continue
+		}
+		f.Fixed = true
+	}
+}
+
+// diagnosis collects the findings for the workspace in dir
+type diagnosis struct {
+	dir      string
+	findings []*Finding
+}
+
+func (d *diagnosis) add(f *Finding) {
+	d.findings = append(d.findings, f)
+}
+
+// open opens the workspace for a fix. Opening applies pending migrations to
+// the workspace database.
+func (d *diagnosis) open() (*Workspace, error) {
+	return OpenWorkspace(d.dir)
+}
+
+// checkEnvironment reports how the home directory and the knox root resolve
+func (d *diagnosis) checkEnvironment() {
+	rootEnv := os.Getenv(vault.KnoxRootEnvVar)
+
+	if _, err := os.UserHomeDir(); err != nil {
+		if rootEnv == "" {
+			d.add(&Finding{
+				Subject:    "environment",
+				Severity:   SeverityError,
+				Problem:    fmt.Sprintf("home directory cannot be resolved: %v", err),
+				Suggestion: fmt.Sprintf("set HOME, or set %s to the directory knox should keep vaults in", vault.KnoxRootEnvVar),
+			})
+			return
+		}
+
+		d.add(&Finding{
+			Subject:    "environment",
+			Severity:   SeverityWarning,
+			Problem:    fmt.Sprintf("home directory cannot be resolved, so vault paths under ~/ will not resolve: %v", err),
+			Suggestion: "set HOME",
+		})
+	}
+
+	if rootEnv != "" && !filepath.IsAbs(rootEnv) {
+		d.add(&Finding{
+			Subject:    "environment",
+			Severity:   SeverityWarning,
+			Problem:    fmt.Sprintf("%s is the relative path '%s', so it resolves differently in each directory", vault.KnoxRootEnvVar, rootEnv),
+			Suggestion: fmt.Sprintf("set %s to an absolute path", vault.KnoxRootEnvVar),
+		})
+	}
+
+	root, err := vault.RootDir()
+	if err != nil {
+		return
+	}
+
+	source := "default"
+	if rootEnv != "" {
+		source = "from " + vault.KnoxRootEnvVar
+	}
+
+	switch {
+	case fs.IsDir(root):
+		d.add(&Finding{
+			Subject:  "environment",
+			Severity: SeverityInfo,
+			Problem:  fmt.Sprintf("knox root is %s (%s)", root, source),
+		})
+	case fs.IsExist(root):
+		d.add(&Finding{
+			Subject:    "environment",
+			Severity:   SeverityError,
+			Problem:    fmt.Sprintf("knox root %s (%s) is not a directory", root, source),
+			Suggestion: fmt.Sprintf("move %s aside, or set %s to a directory", root, vault.KnoxRootEnvVar),
+		})
+	default:
+		d.add(&Finding{
+			Subject:    "environment",
+			Severity:   SeverityInfo,
+			Problem:    fmt.Sprintf("knox root %s (%s) does not exist yet", root, source),
+			Suggestion: "it is created with the first vault made by alias",
+		})
+	}
+}
+
+// checkDatabase checks the workspace database and returns its linked vault
+// paths by alias, or nil when the database cannot be read
+func (d *diagnosis) checkDatabase() map[string]string {
+	dataDir := filepath.Join(d.dir, internal.DataDirectoryName)
+	d.checkPermissions("workspace", dataDir, fs.PrivateDir)
+
+	dbPath := database.NewPath(d.dir)
+	if err := database.Check(dbPath); err != nil {
+		d.add(&Finding{
+			Subject:    "workspace database",
+			Severity:   SeverityError,
+			Problem:    err.Error(),
+			Suggestion: fmt.Sprintf("restore %s from a backup", dbPath),
+		})
+		return nil
+	}
+	d.checkPermissions("workspace database", dbPath.String(), fs.PrivateFile)
+
+	status, err := database.SchemaStatus(dbPath)
+	switch {
+	case err != nil:
+		d.add(schemaFinding("workspace database", err))
+		return nil
+	case !status.UpToDate():
+		d.add(&Finding{
+			Subject:    "workspace database",
+			Severity:   SeverityWarning,
+			Problem:    fmt.Sprintf("schema is at version %d, %d migrations pending", status.Current, len(status.Pending)),
+			Suggestion: "run 'knox migrate'",
+			repair: func() error {
+				_, err := d.open()
+				return err
+			},
+		})
+	}
+
+	linked, err := database.LinkedVaultPaths(dbPath)
+	if err != nil {
+		d.add(&Finding{
+			Subject:    "workspace database",
+			Severity:   SeverityError,
+			Problem:    err.Error(),
+			Suggestion: fmt.Sprintf("restore %s from a backup", dbPath),
+		})
+		return nil
+	}
+
+	return linked
+}
+
+// checkProjects checks that every project file parses and validates, and
+// that its references point at linked vaults. linked is nil when the linked
+// vaults are unknown.
+func (d *diagnosis) checkProjects(linked map[string]string) {
+	projectsDir := filepath.Join(d.dir, internal.DataDirectoryName, internal.ProjectsDirectoryName)
+
+	if !fs.IsDir(projectsDir) {
+		d.add(&Finding{
+			Subject:    "projects",
+			Severity:   SeverityError,
+			Problem:    fmt.Sprintf("projects directory %s is missing", projectsDir),
+			Suggestion: fmt.Sprintf("run 'mkdir -m 700 %s'", projectsDir),
+			repair: func() error {
+				if err := os.MkdirAll(projectsDir, 0700); err != nil {
+					return errs.Wrap(err, error_codes.CreateFailureErrCode, "failed to create projects directory").WithPath(projectsDir)
+				}
+				return nil
+			},
+		})
+		return
+	}
+	d.checkPermissions("projects", projectsDir, fs.PrivateDir)
+
+	entries, err := os.ReadDir(projectsDir)
+	if err != nil {
+		d.add(&Finding{
+			Subject:  "projects",
+			Severity: SeverityError,
+			Problem:  fmt.Sprintf("failed to read projects directory: %v", err),
+		})
+		return
+	}
+
+	names := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		names[name] = true
+		d.checkProject(name, filepath.Join(projectsDir, entry.Name()), linked)
+	}
+
+	current, ok, err := database.ConfigValue(database.NewPath(d.dir), CurrentProjectSetting)
+	if err == nil && ok && !names[current] {
+		d.add(&Finding{
+			Subject:    "workspace",
+			Severity:   SeverityWarning,
+			Problem:    fmt.Sprintf("current project '%s' does not exist", current),
+			Suggestion: "run 'knox switch <project>'",
+		})
+	}
+}
+
+func (d *diagnosis) checkProject(name, path string, linked map[string]string) {
+	subject := fmt.Sprintf("project '%s'", name)
+	d.checkPermissions(subject, path, fs.PrivateFile)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		d.add(&Finding{
+			Subject:  subject,
+			Severity: SeverityError,
+			Problem:  fmt.Sprintf("failed to read %s: %v", path, err),
+		})
+		return
+	}
+
+	project, err := FromJSON(data)
+	if err != nil {
+		d.add(&Finding{
+			Subject:    subject,
+			Severity:   SeverityError,
+			Problem:    fmt.Sprintf("%s is not valid JSON: %v", path, err),
+			Suggestion: fmt.Sprintf("fix %s by hand, or run 'knox undo' if a knox command broke it", path),
+		})
+		return
+	}
+
+	if err := project.Validate(); err != nil {
+		d.add(&Finding{
+			Subject:    subject,
+			Severity:   SeverityError,
+			Problem:    err.Error(),
+			Suggestion: fmt.Sprintf("fix %s by hand", path),
+		})
+		return
+	}
+
+	if project.Name != name {
+		d.add(&Finding{
+			Subject:    subject,
+			Severity:   SeverityWarning,
+			Problem:    fmt.Sprintf("%s names itself '%s'", path, project.Name),
+			Suggestion: "rename the file or its name field so they match",
+		})
+	}
+
+	if linked == nil {
+		return
+	}
+
+	unlinked := make(map[string]bool)
+	for _, ref := range project.SecretMap {
+		r, err := ParseSecretReference(ref)
+		if err == nil && linked[r.Vault] == "" {
+			unlinked[r.Vault] = true
+		}
+	}
+	if len(unlinked) > 0 {
+		d.add(&Finding{
+			Subject:    subject,
+			Severity:   SeverityWarning,
+			Problem:    fmt.Sprintf("references unlinked vaults: %s", strings.Join(sortedKeys(unlinked), ", ")),
+			Suggestion: "run 'knox tidy' to prune or repair the references, or 'knox link' the vaults",
+		})
+	}
+}
+
+// checkVaults checks that every linked vault exists, passes its integrity
+// check, is up to date and is private to the user
+func (d *diagnosis) checkVaults(linked map[string]string) {
+	aliases := make([]string, 0, len(linked))
+	for alias := range linked {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		d.checkVault(alias, expandVaultPath(d.dir, linked[alias]))
+	}
+}
+
+func (d *diagnosis) checkVault(alias, path string) {
+	subject := fmt.Sprintf("vault '%s'", alias)
+
+	if !fs.IsExist(path) {
+		d.add(&Finding{
+			Subject:    subject,
+			Severity:   SeverityError,
+			Problem:    fmt.Sprintf("vault file not found at %s", path),
+			Suggestion: fmt.Sprintf("run 'knox relink %s <path>' if it moved, or 'knox unlink %s'", alias, alias),
+			repair: func() error {
+				ws, err := d.open()
+				if err != nil {
+					return err
+				}
+
+				found, err := ws.RelocateVault(alias)
+				if err != nil {
+					return err
+				}
+				if found == "" {
+					return errs.New(error_codes.VaultNotFoundErrCode, "vault not found by its id in the default vaults directory").WithContext("alias", alias)
+				}
+				return nil
+			},
+		})
+		return
+	}
+
+	if err := vault.CheckIntegrity(path); err != nil {
+		d.add(&Finding{
+			Subject:    subject,
+			Severity:   SeverityError,
+			Problem:    err.Error(),
+			Suggestion: fmt.Sprintf("run 'knox vault restore %s <backup>'", alias),
+		})
+		return
+	}
+	d.checkPermissions(subject, path, fs.PrivateFile)
+
+	status, err := vault.SchemaStatus(path)
+	switch {
+	case err != nil:
+		d.add(schemaFinding(subject, err))
+	case !status.UpToDate():
+		d.add(&Finding{
+			Subject:    subject,
+			Severity:   SeverityWarning,
+			Problem:    fmt.Sprintf("schema is at version %d, %d migrations pending", status.Current, len(status.Pending)),
+			Suggestion: "run 'knox migrate'",
+			repair: func() error {
+				ws, err := d.open()
+				if err != nil {
+					return err
+				}
+
+				v, err := ws.OpenVault(alias)
+				if err != nil {
+					return err
+				}
+				return v.Close()
+			},
+		})
+	}
+}
+
+// checkPermissions reports path when other users may access it
+func (d *diagnosis) checkPermissions(subject, path string, policy fs.Policy) {
+	v, err := policy.Check(path)
+	if err != nil || v == nil {
+		return
+	}
+
+	f := &Finding{
+		Subject:    subject,
+		Severity:   SeverityWarning,
+		Problem:    v.String(),
+		Suggestion: violationHint(v),
+	}
+	if !v.Foreign {
+		f.repair = func() error {
+			_, err := policy.Enforce(path)
+			return err
+		}
+	}
+	d.add(f)
+}
+
+// schemaFinding reports a schema that cannot be inspected
+func schemaFinding(subject string, err error) *Finding {
+	f := &Finding{Subject: subject, Severity: SeverityError, Problem: err.Error()}
+	if errs.Is(err, migrate.SchemaTooNewErrCode) {
+		f.Suggestion = "upgrade knox"
+	}
+	return f
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,92 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace/internal"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+func TestDiagnoseOrdersBySeverity(t *testing.T) {
+	// The knox root is checked first and reports an info finding
+	t.Setenv(vault.KnoxRootEnvVar, t.TempDir())
+
+	dir := t.TempDir()
+	ws, err := CreateWorkspace(dir)
+	errs.AssertNoError(t, err)
+
+	// Two linked vaults whose files are gone report errors, in alias order
+	for _, alias := range []string{"a", "b"} {
+		path := filepath.Join(dir, alias+".db")
+		v, err := vault.Create(path, vault.CreateOptions{})
+		errs.AssertNoError(t, err)
+		errs.AssertNoError(t, v.Close())
+		errs.AssertNoError(t, ws.LinkVault(alias, path))
+		errs.AssertNoError(t, os.Remove(path))
+	}
+
+	// A readable projects directory reports a warning before the vaults are checked
+	projectsDir := filepath.Join(dir, internal.DataDirectoryName, internal.ProjectsDirectoryName)
+	errs.AssertNoError(t, os.Chmod(projectsDir, 0755))
+
+	want := []struct {
+		subject  string
+		severity Severity
+	}{
+		{subject: "vault 'a'", severity: SeverityError},
+		{subject: "vault 'b'", severity: SeverityError},
+		{subject: "projects", severity: SeverityWarning},
+		{subject: "environment", severity: SeverityInfo},
+	}
+
+	findings := Diagnose(dir)
+	if len(findings) != len(want) {
+		for _, f := range findings {
+			t.Logf("[%s] %s: %s", f.Severity, f.Subject, f.Problem)
+		}
+		t.Fatalf("Diagnose() returned %d findings, want %d", len(findings), len(want))
+	}
+
+	for i, w := range want {
+		if findings[i].Subject != w.subject || findings[i].Severity != w.severity {
+			t.Errorf("finding %d = [%s] %s, want [%s] %s", i, findings[i].Severity, findings[i].Subject, w.severity, w.subject)
+		}
+	}
+}
+
+func TestDiagnoseWithoutHome(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     string
+		severity Severity
+	}{
+		{name: "knox root set", root: t.TempDir(), severity: SeverityWarning},
+		{name: "knox root unset", root: "", severity: SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", "")
+			t.Setenv(vault.KnoxRootEnvVar, tt.root)
+
+			findings := Diagnose("")
+
+			var home *Finding
+			for _, f := range findings {
+				if f.Subject == "environment" && strings.Contains(f.Problem, "home directory cannot be resolved") {
+					home = f
+				}
+			}
+			if home == nil {
+				t.Fatalf("Diagnose() did not report the missing home directory: %v", findings)
+			}
+			if home.Severity != tt.severity {
+				t.Errorf("home finding severity = %s, want %s", home.Severity, tt.severity)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	workspaceErrors "github.com/tomdoesdev/knox/internal/workspace/internal"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/tomdoesdev/knox/kit/fs"
+)
+
+// Check verifies that the workspace database at path has the workspace tables
+// and passes SQLite's integrity check. The database is opened read only.
+func Check(path *Path) error {
+	if !fs.IsFile(path.String()) {
+		return errs.New(error_codes.FileNotFoundErrCode, "workspace database not found").WithContext("path", path)
+	}
+
+	db, err := sql.Open("sqlite3", path.ConnectionString("mode=ro"))
+	if err != nil {
+		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to open database").WithContext("path", path)
+	}
+	defer func(db *sql.DB) {
+		_ = db.Close()
+	}(db)
+
+	if !isValidWorkspaceDatabase(db) {
+		return workspaceErrors.ErrInvalidDatabase.WithContext("path", path)
+	}
+
+	rows, err := db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to run integrity check").WithContext("path", path)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to scan integrity check result")
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to run integrity check").WithContext("path", path)
+	}
+
+	if len(problems) > 0 {
+		return errs.New(error_codes.DatabaseFailureErrCode, "workspace database failed integrity check").
+			WithContext("path", path).
+			WithContext("result", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// ConfigValue returns the stored value of a workspace setting from the
+// database at path without migrating it. ok is false when the setting is not set.
+func ConfigValue(path *Path, key string) (value string, ok bool, err error) {
+	db, err := sql.Open("sqlite3", path.ConnectionString("mode=ro"))
+	if err != nil {
+		return "", false, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to open database").WithContext("path", path)
+	}
+	defer func(db *sql.DB) {
+		_ = db.Close()
+	}(db)
+
+	err = db.QueryRow("SELECT value FROM workspace_settings WHERE key = ? AND category = 'config'", key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to get setting").WithContext("key", key)
+	}
+
+	return value, true, nil
+}
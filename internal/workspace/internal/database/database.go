@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -100,13 +101,21 @@ func CreateWorkspaceDatabase(path *Path) (*Database, error) {
 		return nil, errs.Wrap(workspaceErrors.ErrInvalidDatabase, error_codes.DatabaseFailureErrCode, "database already exists")
 	}
 
+	// Create the file first so SQLite does not create it with the umask's permissions
+	f, err := os.OpenFile(path.String(), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.CreateFailureErrCode, "failed to create database file").WithContext("path", path)
+	}
+	if err := f.Close(); err != nil {
+		return nil, errs.Wrap(err, error_codes.CreateFailureErrCode, "failed to create database file").WithContext("path", path)
+	}
+
 	db, err := sql.Open("sqlite3", path.ConnectionString())
 	if err != nil {
 		return nil, errs.Wrap(err, error_codes.CreateFailureErrCode, "failed to open database").WithContext("path", path)
 	}
 
-	_, err = db.Exec(tablesSchema)
-	if err != nil {
+	if _, err := migrations.Up(db); err != nil {
 		return nil, errs.Wrap(err, error_codes.CreateFailureErrCode, "failed to create database").WithContext("path", path)
 	}
 
@@ -127,6 +136,11 @@ func OpenWorkspaceDatabase(path *Path) (*Database, error) {
 		return nil, workspaceErrors.ErrInvalidDatabase.WithContext("path", path)
 	}
 
+	if err := migrateDatabase(db, path); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
 	return &Database{db: db}, nil
 }
 
@@ -147,12 +161,7 @@ func isValidWorkspaceDatabase(db *sql.DB) bool {
 }
 
 func IsDatabaseExists(path *Path) bool {
-	exists, err := fs.IsExist(path.String())
-	if err != nil {
-		return false
-	}
-
-	if !exists {
+	if !fs.IsExist(path.String()) {
 		return false
 	}
 
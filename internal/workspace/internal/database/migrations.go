@@ -0,0 +1,88 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	workspaceErrors "github.com/tomdoesdev/knox/internal/workspace/internal"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/tomdoesdev/knox/kit/migrate"
+)
+
+// migrations upgrade a workspace database schema in order. Workspaces created
+// before versioning report user_version 0 and already have the tables from
+// version 1, so it must be safe to re-run.
+var migrations = migrate.New(
+	migrate.Migration{
+		Version:     1,
+		Description: "create linked_vaults and workspace_settings tables",
+		Up:          migrate.SQL(tablesSchema),
+	},
+	migrate.Migration{
+		Version:     2,
+		Description: "record vault ids on linked vaults",
+		Up: migrate.SQL(`
+			ALTER TABLE linked_vaults ADD COLUMN vault_id TEXT NOT NULL DEFAULT '';
+		`),
+	},
+)
+
+// SchemaStatus reports the schema version of the workspace database at path and
+// any pending migrations without applying them
+func SchemaStatus(path *Path) (migrate.Status, error) {
+	db, err := sql.Open("sqlite3", path.ConnectionString("mode=ro"))
+	if err != nil {
+		return migrate.Status{}, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to open database").WithContext("path", path)
+	}
+	defer func(db *sql.DB) {
+		_ = db.Close()
+	}(db)
+
+	if !isValidWorkspaceDatabase(db) {
+		return migrate.Status{}, workspaceErrors.ErrInvalidDatabase.WithContext("path", path)
+	}
+
+	return migrations.Status(db)
+}
+
+// LinkedVaultPaths returns the alias to path mapping of the workspace database
+// at path without migrating it
+func LinkedVaultPaths(path *Path) (map[string]string, error) {
+	db, err := sql.Open("sqlite3", path.ConnectionString("mode=ro"))
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to open database").WithContext("path", path)
+	}
+	defer func(db *sql.DB) {
+		_ = db.Close()
+	}(db)
+
+	rows, err := db.Query("SELECT alias, path FROM linked_vaults")
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to query linked vaults").WithContext("path", path)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	paths := make(map[string]string)
+	for rows.Next() {
+		var alias, vaultPath string
+		if err := rows.Scan(&alias, &vaultPath); err != nil {
+			return nil, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to scan vault row")
+		}
+		paths[alias] = vaultPath
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "error iterating linked vaults")
+	}
+
+	return paths, nil
+}
+
+func migrateDatabase(db *sql.DB, path *Path) error {
+	if _, err := migrations.Up(db); err != nil {
+		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to migrate workspace database").WithContext("path", path)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+// Prefixes of portable vault paths. Paths without a prefix that are not
+// absolute are relative to the workspace directory.
+const (
+	homePathPrefix = "~/"
+	rootPathPrefix = "$" + vault.KnoxRootEnvVar + "/"
+)
+
+// expandVaultPath turns a vault path as stored in linked_vaults into an
+// absolute path. Stored paths are either absolute, relative to the user's home
+// directory (~/), relative to the knox root ($KNOX_ROOT/), or relative to the
+// workspace directory dir.
+func expandVaultPath(dir, stored string) string {
+	switch {
+	case strings.HasPrefix(stored, rootPathPrefix):
+		root, err := vault.RootDir()
+		if err != nil {
+			return stored
+		}
+		return filepath.Join(root, strings.TrimPrefix(stored, rootPathPrefix))
+	case strings.HasPrefix(stored, homePathPrefix):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return stored
+		}
+		return filepath.Join(home, strings.TrimPrefix(stored, homePathPrefix))
+	case filepath.IsAbs(stored):
+		return filepath.Clean(stored)
+	default:
+		return filepath.Join(dir, stored)
+	}
+}
+
+// PortableVaultPath returns the shortest portable form of the absolute vault
+// path absPath: relative to the workspace directory when the vault lives
+// inside it, then relative to the knox root, then to the user's home
+// directory. Paths outside all three cannot be made portable.
+func (w *Workspace) PortableVaultPath(absPath string) (string, error) {
+	if rel, ok := relativeTo(w.Dir(), absPath); ok {
+		return rel, nil
+	}
+
+	if root, err := vault.RootDir(); err == nil {
+		if rel, ok := relativeTo(root, absPath); ok {
+			return rootPathPrefix + rel, nil
+		}
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, ok := relativeTo(home, absPath); ok {
+			return homePathPrefix + rel, nil
+		}
+	}
+
+	return "", errs.New(error_codes.ValidationErrCode, "vault path is outside the workspace, knox root and home directory").
+		WithContext("path", absPath)
+}
+
+// ExpandVaultPath returns the absolute path of a vault path as stored on a link
+func (w *Workspace) ExpandVaultPath(stored string) string {
+	return expandVaultPath(w.Dir(), stored)
+}
+
+// relativeTo returns path relative to base when path lies inside base
+func relativeTo(base, path string) (string, bool) {
+	rel, err := filepath.Rel(base, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
@@ -0,0 +1,143 @@
+package workspace
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace/internal"
+	"github.com/tomdoesdev/knox/internal/workspace/internal/database"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/tomdoesdev/knox/kit/fs"
+)
+
+// Warn reports problems that do not stop a command, such as workspace files
+// other users can read. By default warnings are only logged at debug level;
+// the CLI prints them to stderr.
+var Warn = func(format string, args ...any) {
+	slog.Debug(fmt.Sprintf(format, args...))
+}
+
+// warned holds the paths already warned about, since a workspace may be
+// opened several times by one command
+var warned = make(map[string]bool)
+
+// PrivatePath is a file or directory that only its owner should be able to access
+type PrivatePath struct {
+	// Kind describes the path, such as "workspace database" or "vault 'v1'"
+	Kind   string
+	Path   string
+	Policy fs.Policy
+}
+
+// PrivatePaths lists the paths of the workspace in dir that must be private,
+// without opening it: the workspace data and projects directories, its
+// database, every project file, every linked vault file with its SQLite
+// sidecar files, and the knox root and default vaults directories. Paths that
+// do not exist are left out.
+func PrivatePaths(dir string) ([]PrivatePath, error) {
+	dataDir := filepath.Join(dir, internal.DataDirectoryName)
+	projectsDir := filepath.Join(dataDir, internal.ProjectsDirectoryName)
+	dbPath := database.NewPath(dir)
+
+	candidates := []PrivatePath{
+		{Kind: "workspace", Path: dataDir, Policy: fs.PrivateDir},
+		{Kind: "projects", Path: projectsDir, Policy: fs.PrivateDir},
+	}
+	candidates = append(candidates, sqlitePaths("workspace database", dbPath.String())...)
+
+	entries, err := os.ReadDir(projectsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errs.Wrap(err, error_codes.DirectoryInvalidErrCode, "failed to read projects directory").WithPath(projectsDir)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".json" {
+			candidates = append(candidates, PrivatePath{
+				Kind:   "project file",
+				Path:   filepath.Join(projectsDir, entry.Name()),
+				Policy: fs.PrivateFile,
+			})
+		}
+	}
+
+	linked, err := database.LinkedVaultPaths(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	aliases := make([]string, 0, len(linked))
+	for alias := range linked {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		candidates = append(candidates, sqlitePaths(fmt.Sprintf("vault '%s'", alias), expandVaultPath(dir, linked[alias]))...)
+	}
+
+	if root, err := vault.RootDir(); err == nil {
+		candidates = append(candidates,
+			PrivatePath{Kind: "knox root", Path: root, Policy: fs.PrivateDir},
+			PrivatePath{Kind: "vaults directory", Path: filepath.Join(root, vault.VaultsDirName), Policy: fs.PrivateDir},
+		)
+	}
+
+	paths := make([]PrivatePath, 0, len(candidates))
+	for _, p := range candidates {
+		if fs.IsExist(p.Path) {
+			paths = append(paths, p)
+		}
+	}
+
+	return paths, nil
+}
+
+// sqlitePaths returns the private paths of a SQLite database and the sidecar
+// files SQLite keeps next to it
+func sqlitePaths(kind, path string) []PrivatePath {
+	paths := make([]PrivatePath, 0, 3)
+	for _, p := range []string{path, path + "-wal", path + "-shm"} {
+		paths = append(paths, PrivatePath{Kind: kind, Path: p, Policy: fs.PrivateFile})
+	}
+	return paths
+}
+
+// checkPermissions applies the permission_policy setting to p. Only vaults
+// are refused, so a workspace with loose permissions can still be opened and
+// fixed.
+func (w *Workspace) checkPermissions(p PrivatePath, refusable bool) error {
+	policy, err := w.GetConfig(PermissionPolicySetting)
+	if err != nil {
+		return err
+	}
+	if policy == PermissionPolicyIgnore {
+		return nil
+	}
+
+	// Missing paths are reported by whatever opens them
+	v, err := p.Policy.Check(p.Path)
+	if err != nil || v == nil {
+		return nil
+	}
+
+	if policy == PermissionPolicyRefuse && refusable {
+		return errs.New(error_codes.InsecurePermissionsErrCode, fmt.Sprintf("refusing to open %s: %s, %s", p.Kind, v, violationHint(v))).
+			WithContext(PermissionPolicySetting, policy)
+	}
+
+	if !warned[p.Path] {
+		warned[p.Path] = true
+		Warn("%s, %s", v, violationHint(v))
+	}
+	return nil
+}
+
+// violationHint tells the user how to fix v
+func violationHint(v *fs.Violation) string {
+	if v.Foreign {
+		return "its owner must be changed to the current user"
+	}
+	return "run 'knox fix-perms' to fix"
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,11 +20,19 @@ type Project struct {
 	SecretMap   map[string]string `json:"secret_map"`
 }
 
-// SecretReference represents a parsed secret reference in format "secret@vault/collection"
+// SecretReference represents a parsed secret reference in format "secret@vault/collection",
+// optionally pinned to a version with a "#N" suffix
 type SecretReference struct {
 	Secret     string
 	Vault      string
 	Collection string
+	Version    int // 0 means the latest version
+}
+
+// CollectionReference represents a parsed collection target in format "collection@vault"
+type CollectionReference struct {
+	Collection string
+	Vault      string
 }
 
 // NewProject creates a new project with the given name and description
@@ -77,6 +86,7 @@ func FromJSON(data []byte) (*Project, error) {
 }
 
 // ParseSecretReference parses a secret reference in format "secret@vault/collection"
+// or "secret@vault/collection#version"
 func ParseSecretReference(ref string) (*SecretReference, error) {
 	parts := strings.Split(ref, "@")
 	if len(parts) != 2 {
@@ -94,6 +104,17 @@ func ParseSecretReference(ref string) (*SecretReference, error) {
 		return nil, errs.New(error_codes.SecretInvalidErrCode, "vault/collection location cannot be empty").WithContext("reference", ref)
 	}
 
+	// Parse an optional #version suffix from location
+	version := 0
+	if i := strings.LastIndex(location, "#"); i >= 0 {
+		n, err := strconv.Atoi(strings.TrimSpace(location[i+1:]))
+		if err != nil || n < 1 {
+			return nil, errs.New(error_codes.SecretInvalidErrCode, "secret version must be a positive number").WithContext("reference", ref)
+		}
+		version = n
+		location = strings.TrimSpace(location[:i])
+	}
+
 	// Parse vault/collection from location
 	locationParts := strings.Split(location, "/")
 	if len(locationParts) != 2 {
@@ -115,9 +136,49 @@ func ParseSecretReference(ref string) (*SecretReference, error) {
 		Secret:     secret,
 		Vault:      vault,
 		Collection: collection,
+		Version:    version,
+	}, nil
+}
+
+// String returns the reference in "secret@vault/collection" format, with a "#N"
+// suffix when pinned to a version
+func (r *SecretReference) String() string {
+	s := r.Secret + "@" + r.Vault + "/" + r.Collection
+	if r.Version > 0 {
+		s += "#" + strconv.Itoa(r.Version)
+	}
+	return s
+}
+
+// ParseCollectionReference parses a collection target in format "collection@vault"
+func ParseCollectionReference(ref string) (*CollectionReference, error) {
+	parts := strings.Split(ref, "@")
+	if len(parts) != 2 {
+		return nil, errs.New(error_codes.ValidationErrCode, "invalid collection reference format, expected 'collection@vault'").WithContext("reference", ref)
+	}
+
+	collection := strings.TrimSpace(parts[0])
+	vault := strings.TrimSpace(parts[1])
+
+	if collection == "" {
+		return nil, errs.New(error_codes.ValidationErrCode, "collection name cannot be empty").WithContext("reference", ref)
+	}
+
+	if vault == "" {
+		return nil, errs.New(error_codes.ValidationErrCode, "vault name cannot be empty").WithContext("reference", ref)
+	}
+
+	return &CollectionReference{
+		Collection: collection,
+		Vault:      vault,
 	}, nil
 }
 
+// String returns the reference in "collection@vault" format
+func (r *CollectionReference) String() string {
+	return r.Collection + "@" + r.Vault
+}
+
 // ValidateName validates a project name
 func ValidateName(name string) error {
 	if name == "" {
@@ -155,7 +216,7 @@ func (p *Project) Validate() error {
 
 		_, err := ParseSecretReference(secretRef)
 		if err != nil {
-			return errs.Wrap(err, error_codes.ProjectInvalidErrCode, fmt.Sprintf("invalid secret reference for '%s'", logicalName))
+			return errs.Wrap(err, error_codes.ProjectInvalidErrCode, "invalid secret reference for '%s'", logicalName)
 		}
 	}
 
@@ -0,0 +1,50 @@
+package workspace
+
+import (
+	"sort"
+
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/internal/workspace/internal/database"
+	"github.com/tomdoesdev/knox/kit/migrate"
+)
+
+// SchemaReport describes the schema state of the workspace database or of one linked vault
+type SchemaReport struct {
+	Name   string
+	Path   string
+	Status migrate.Status
+	Err    error
+}
+
+// InspectSchemas reports the schema state of the workspace in dir and of every
+// linked vault without migrating anything. The workspace database comes first,
+// followed by vaults ordered by alias.
+func InspectSchemas(dir string) ([]SchemaReport, error) {
+	dbPath := database.NewPath(dir)
+
+	status, err := database.SchemaStatus(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := []SchemaReport{{Name: "workspace", Path: dbPath.String(), Status: status}}
+
+	vaultPaths, err := database.LinkedVaultPaths(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make([]string, 0, len(vaultPaths))
+	for alias := range vaultPaths {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		report := SchemaReport{Name: alias, Path: expandVaultPath(dir, vaultPaths[alias])}
+		report.Status, report.Err = vault.SchemaStatus(report.Path)
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,333 @@
+package workspace
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/workspace/internal/database"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/tomdoesdev/knox/kit/fs"
+)
+
+// SettingType is the type a setting value is stored as
+type SettingType string
+
+const (
+	SettingString SettingType = "string"
+	SettingBool   SettingType = "bool"
+	SettingInt    SettingType = "int"
+	// SettingList is a comma separated list of strings
+	SettingList SettingType = "list"
+)
+
+// Setting names
+const (
+	CurrentProjectSetting      = "current_project"
+	DefaultOutputFormatSetting = "default_output_format"
+	RunEnvAllowlistSetting     = "run_env_allowlist"
+	SnapshotRetentionSetting   = "snapshot_retention"
+	PermissionPolicySetting    = "permission_policy"
+)
+
+// OutputFormats are the values accepted by the default_output_format setting
+var OutputFormats = []string{"text", "json", "yaml"}
+
+// Values of the permission_policy setting, deciding what happens when a linked
+// vault file can be accessed by other users
+const (
+	PermissionPolicyWarn   = "warn"
+	PermissionPolicyRefuse = "refuse"
+	PermissionPolicyIgnore = "ignore"
+)
+
+// SettingDefinition describes a workspace setting. Values are stored as text
+// and checked against Type, then Validator, before they are saved.
+type SettingDefinition struct {
+	Key          string
+	Type         SettingType
+	DefaultValue string
+	Description  string
+	Validator    func(value string) error
+}
+
+// DefaultEnvAllowlist is the set of parent environment variables passed to
+// the child process of knox run when run_env_allowlist is not set
+var DefaultEnvAllowlist = []string{"PATH", "HOME", "USER", "SHELL", "TERM", "LANG", "TMPDIR"}
+
+// Settings is the registry of every workspace setting
+var Settings = map[string]SettingDefinition{
+	CurrentProjectSetting: {
+		Key:         CurrentProjectSetting,
+		Type:        SettingString,
+		Description: "Project used when no project is given",
+		Validator:   ValidateName,
+	},
+	DefaultOutputFormatSetting: {
+		Key:          DefaultOutputFormatSetting,
+		Type:         SettingString,
+		DefaultValue: "text",
+		Description:  "Output format used when --output is not given",
+		Validator:    oneOf(OutputFormats...),
+	},
+	RunEnvAllowlistSetting: {
+		Key:          RunEnvAllowlistSetting,
+		Type:         SettingList,
+		DefaultValue: strings.Join(DefaultEnvAllowlist, ","),
+		Description:  "Environment variables passed through by knox run, NAME or PREFIX*",
+	},
+	SnapshotRetentionSetting: {
+		Key:          SnapshotRetentionSetting,
+		Type:         SettingInt,
+		DefaultValue: "20",
+		Description:  "Automatic snapshots kept for knox undo, 0 disables them",
+		Validator:    nonNegative,
+	},
+	PermissionPolicySetting: {
+		Key:          PermissionPolicySetting,
+		Type:         SettingString,
+		DefaultValue: PermissionPolicyWarn,
+		Description:  "What to do when a vault file can be accessed by other users: warn, refuse or ignore",
+		Validator:    oneOf(PermissionPolicyWarn, PermissionPolicyRefuse, PermissionPolicyIgnore),
+	},
+}
+
+// LookupSetting returns the definition of a registered setting
+func LookupSetting(key string) (SettingDefinition, error) {
+	def, ok := Settings[key]
+	if !ok {
+		return SettingDefinition{}, errs.New(error_codes.SettingUnknownErrCode, "unknown setting").
+			WithContext("key", key).
+			WithContext("known", strings.Join(SettingKeys(), ", "))
+	}
+
+	return def, nil
+}
+
+// SettingKeys returns the names of all registered settings, sorted
+func SettingKeys() []string {
+	keys := make([]string, 0, len(Settings))
+	for key := range Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Validate checks that value has the setting's type and passes its validator
+func (d SettingDefinition) Validate(value string) error {
+	var err error
+
+	switch d.Type {
+	case SettingBool:
+		_, err = strconv.ParseBool(value)
+	case SettingInt:
+		_, err = strconv.Atoi(value)
+	}
+	if err == nil && d.Validator != nil {
+		err = d.Validator(value)
+	}
+
+	if err != nil {
+		return errs.Wrap(err, error_codes.SettingInvalidErrCode, "invalid setting value").
+			WithContext("key", d.Key).
+			WithContext("type", d.Type).
+			WithContext("value", value)
+	}
+
+	return nil
+}
+
+// ConfigValue is the effective value of a setting in a workspace
+type ConfigValue struct {
+	SettingDefinition
+	Value string
+	IsSet bool
+}
+
+// GetConfig returns the value of a setting, or its default when it is not set
+func (w *Workspace) GetConfig(key string) (string, error) {
+	value, err := w.GetConfigValue(key)
+	if err != nil {
+		return "", err
+	}
+
+	return value.Value, nil
+}
+
+// GetConfigBool returns the value of a bool setting
+func (w *Workspace) GetConfigBool(key string) (bool, error) {
+	value, err := w.typedConfig(key, SettingBool)
+	if err != nil {
+		return false, err
+	}
+
+	return strconv.ParseBool(value)
+}
+
+// GetConfigInt returns the value of an int setting
+func (w *Workspace) GetConfigInt(key string) (int, error) {
+	value, err := w.typedConfig(key, SettingInt)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(value)
+}
+
+// GetConfigList returns the entries of a list setting with surrounding
+// whitespace and empty entries removed
+func (w *Workspace) GetConfigList(key string) ([]string, error) {
+	value, err := w.typedConfig(key, SettingList)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]string, 0)
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+
+	return list, nil
+}
+
+// SetConfig validates value against the registry and stores it
+func (w *Workspace) SetConfig(key, value string) error {
+	def, err := LookupSetting(key)
+	if err != nil {
+		return err
+	}
+	if err := def.Validate(value); err != nil {
+		return err
+	}
+	if key == CurrentProjectSetting && !fs.IsFile(w.ProjectPath(value)) {
+		return errs.New(error_codes.ProjectNotFoundErrCode, "project not found").WithContext("name", value)
+	}
+
+	query := `
+		INSERT INTO workspace_settings (key, value, category, updated_at) 
+		VALUES (?, ?, 'config', CURRENT_TIMESTAMP)
+		ON CONFLICT(key) DO UPDATE SET 
+			value = excluded.value,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	_, err = w.db.DB().Exec(query, key, value)
+	if err != nil {
+		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to set setting").WithContext("key", key).WithContext("value", value)
+	}
+
+	return nil
+}
+
+// UnsetConfig removes a stored setting so its default applies again
+func (w *Workspace) UnsetConfig(key string) error {
+	if _, err := LookupSetting(key); err != nil {
+		return err
+	}
+
+	_, err := w.db.DB().Exec("DELETE FROM workspace_settings WHERE key = ? AND category = 'config'", key)
+	if err != nil {
+		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to unset setting").WithContext("key", key)
+	}
+
+	return nil
+}
+
+// ListConfig returns the effective value of every registered setting, sorted by key
+func (w *Workspace) ListConfig() ([]ConfigValue, error) {
+	keys := SettingKeys()
+
+	values := make([]ConfigValue, 0, len(keys))
+	for _, key := range keys {
+		value, err := w.GetConfigValue(key)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// GetConfigValue returns the effective value of a setting together with its
+// definition and whether it is set in the workspace
+func (w *Workspace) GetConfigValue(key string) (ConfigValue, error) {
+	def, err := LookupSetting(key)
+	if err != nil {
+		return ConfigValue{}, err
+	}
+
+	query := "SELECT value FROM workspace_settings WHERE key = ? AND category = 'config'"
+
+	var value string
+	err = w.db.DB().QueryRow(query, key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ConfigValue{SettingDefinition: def, Value: def.DefaultValue}, nil
+		}
+		return ConfigValue{}, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to get setting").WithContext("key", key)
+	}
+
+	return ConfigValue{SettingDefinition: def, Value: value, IsSet: true}, nil
+}
+
+// ReadConfig returns the effective value of a setting in the workspace in dir
+// without opening it, so reading a setting never migrates the database
+func ReadConfig(dir, key string) (string, error) {
+	def, err := LookupSetting(key)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok, err := database.ConfigValue(database.NewPath(dir), key)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return def.DefaultValue, nil
+	}
+
+	return value, nil
+}
+
+// typedConfig returns the raw value of key after checking it is registered with type t
+func (w *Workspace) typedConfig(key string, t SettingType) (string, error) {
+	def, err := LookupSetting(key)
+	if err != nil {
+		return "", err
+	}
+	if def.Type != t {
+		return "", errs.New(error_codes.SettingInvalidErrCode, "setting has a different type").
+			WithContext("key", key).
+			WithContext("type", def.Type).
+			WithContext("requested", t)
+	}
+
+	return w.GetConfig(key)
+}
+
+func oneOf(allowed ...string) func(string) error {
+	return func(value string) error {
+		for _, a := range allowed {
+			if value == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+	}
+}
+
+func nonNegative(value string) error {
+	if n, _ := strconv.Atoi(value); n < 0 {
+		return fmt.Errorf("must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package workspace
+
+import (
+	"github.com/tomdoesdev/knox/internal/snapshot"
+)
+
+// Snapshots returns the workspace's snapshot store, keeping as many snapshots
+// as the snapshot_retention setting allows
+func (w *Workspace) Snapshots() (*snapshot.Store, error) {
+	retention, err := w.GetConfigInt(SnapshotRetentionSetting)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot.Open(w.Dir(), retention)
+}
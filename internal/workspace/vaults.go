@@ -0,0 +1,330 @@
+package workspace
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+
+	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
+	"github.com/tomdoesdev/knox/kit/errs"
+	"github.com/tomdoesdev/knox/kit/fs"
+)
+
+// GetLinkedVault returns the linked vault registered under alias
+func (w *Workspace) GetLinkedVault(alias string) (*LinkedVault, error) {
+	query := "SELECT alias, path, vault_id, created_at FROM linked_vaults WHERE alias = ?"
+
+	var linked LinkedVault
+	err := w.db.DB().QueryRow(query, alias).Scan(&linked.Alias, &linked.StoredPath, &linked.VaultID, &linked.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.New(error_codes.VaultNotFoundErrCode, "vault alias is not linked to workspace").WithContext("alias", alias)
+		}
+		return nil, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to get linked vault").WithContext("alias", alias)
+	}
+	linked.Path = w.ExpandVaultPath(linked.StoredPath)
+
+	return &linked, nil
+}
+
+// UnlinkVault removes the link registered under alias. Project references to
+// the alias are left as they are.
+func (w *Workspace) UnlinkVault(alias string) error {
+	result, err := w.db.DB().Exec("DELETE FROM linked_vaults WHERE alias = ?", alias)
+	if err != nil {
+		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to unlink vault").WithContext("alias", alias)
+	}
+
+	return expectLinkAffected(result, alias)
+}
+
+// SetLinkedVaultPath points the link registered under alias at a new vault
+// path and records the id of the vault found there. Like LinkVault, vaultPath
+// is stored as given.
+func (w *Workspace) SetLinkedVaultPath(alias, vaultPath string) error {
+	vaultID, err := vault.ReadID(w.ExpandVaultPath(vaultPath))
+	if err != nil {
+		return errs.Wrap(err, error_codes.VaultConnectionErrCode, "failed to read vault id").WithContext("path", vaultPath)
+	}
+
+	if err := w.expectPathNotLinked(vaultPath, alias); err != nil {
+		return err
+	}
+
+	result, err := w.db.DB().Exec("UPDATE linked_vaults SET path = ?, vault_id = ? WHERE alias = ?", vaultPath, vaultID, alias)
+	if err != nil {
+		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to update linked vault").
+			WithContext("alias", alias).
+			WithContext("path", vaultPath)
+	}
+
+	return expectLinkAffected(result, alias)
+}
+
+// expectPathNotLinked rejects vaultPath when a link other than the one under
+// alias already points at the same file, whatever form either path is stored in
+func (w *Workspace) expectPathNotLinked(vaultPath, alias string) error {
+	vaults, err := w.GetLinkedVaults()
+	if err != nil {
+		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to check vault path").WithContext("path", vaultPath)
+	}
+
+	absPath := w.ExpandVaultPath(vaultPath)
+	for _, lv := range vaults {
+		if lv.Alias != alias && lv.Path == absPath {
+			return errs.New(error_codes.ValidationErrCode, "vault path already linked").
+				WithContext("path", vaultPath).
+				WithContext("alias", lv.Alias)
+		}
+	}
+
+	return nil
+}
+
+// RelocateVault looks for the vault linked under alias in the default vaults
+// directory when its linked path no longer exists, matching on the vault id
+// recorded at link time. When found, the link is updated and the new path is
+// returned. An empty path means the vault is where it was linked, or could not
+// be found.
+func (w *Workspace) RelocateVault(alias string) (string, error) {
+	linked, err := w.GetLinkedVault(alias)
+	if err != nil {
+		return "", err
+	}
+	if linked.VaultID == "" || fs.IsFile(linked.Path) {
+		return "", nil
+	}
+
+	dir, err := vault.DefaultVaultsDir()
+	if err != nil {
+		return "", err
+	}
+
+	path, err := vault.FindByID(dir, linked.VaultID)
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	// Keep the link portable if it was
+	stored := path
+	if !filepath.IsAbs(linked.StoredPath) {
+		if portable, err := w.PortableVaultPath(path); err == nil {
+			stored = portable
+		}
+	}
+
+	if err := w.SetLinkedVaultPath(alias, stored); err != nil {
+		return "", err
+	}
+
+	slog.Debug("relocated linked vault", slog.String("alias", alias), slog.String("from", linked.Path), slog.String("to", path))
+	return path, nil
+}
+
+// recordVaultID stores the id of v on a link made before vaults carried ids
+func (w *Workspace) recordVaultID(linked *LinkedVault, v *vault.Vault) error {
+	if linked.VaultID != "" {
+		return nil
+	}
+
+	id, err := v.ID()
+	if err != nil || id == "" {
+		return err
+	}
+
+	_, err = w.db.DB().Exec("UPDATE linked_vaults SET vault_id = ? WHERE alias = ?", id, linked.Alias)
+	if err != nil {
+		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to record vault id").WithContext("alias", linked.Alias)
+	}
+
+	linked.VaultID = id
+	return nil
+}
+
+func expectLinkAffected(result sql.Result, alias string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to update linked vault").WithContext("alias", alias)
+	}
+	if affected == 0 {
+		return errs.New(error_codes.VaultNotFoundErrCode, "vault alias is not linked to workspace").WithContext("alias", alias)
+	}
+	return nil
+}
+
+// OpenVault opens the vault linked under alias. When the linked path no longer
+// exists, the vault is looked for in the default vaults directory first, see
+// RelocateVault. The caller is responsible for closing it.
+func (w *Workspace) OpenVault(alias string) (*vault.Vault, error) {
+	if _, err := w.RelocateVault(alias); err != nil {
+		return nil, err
+	}
+
+	linked, err := w.GetLinkedVault(alias)
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(linked.Path) {
+		return nil, errs.New(error_codes.VaultConnectionErrCode, "linked vault path cannot be resolved, the home directory or knox root is unknown").
+			WithContext("alias", alias).
+			WithContext("path", linked.StoredPath)
+	}
+
+	private := PrivatePath{Kind: fmt.Sprintf("vault '%s'", alias), Path: linked.Path, Policy: fs.PrivateFile}
+	if err := w.checkPermissions(private, true); err != nil {
+		return nil, err
+	}
+
+	v, err := vault.Open(vault.NewPathDatasource(linked.Path))
+	if err != nil {
+		return nil, errs.Wrap(err, error_codes.VaultConnectionErrCode, "failed to open linked vault").
+			WithContext("alias", alias).
+			WithContext("path", linked.Path)
+	}
+
+	if err := w.recordVaultID(linked, v); err != nil {
+		_ = v.Close()
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ReferenceProblem describes a project secret whose reference cannot be found
+// in its linked vault
+type ReferenceProblem struct {
+	Name      string
+	Reference string
+	Err       error
+}
+
+// CheckSecretReferences verifies that every entry of a project secret map
+// points at an existing vault, collection and key. Values are never read so
+// encrypted vaults do not need to be unlocked. Problems are returned sorted by
+// logical name.
+func (w *Workspace) CheckSecretReferences(secretMap map[string]string) []ReferenceProblem {
+	names := make([]string, 0, len(secretMap))
+	for name := range secretMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	vaults := make(map[string]*vault.Vault)
+	openErrs := make(map[string]error)
+	defer func() {
+		for _, v := range vaults {
+			_ = v.Close()
+		}
+	}()
+
+	var problems []ReferenceProblem
+	for _, name := range names {
+		raw := secretMap[name]
+
+		ref, err := ParseSecretReference(raw)
+		if err != nil {
+			problems = append(problems, ReferenceProblem{Name: name, Reference: raw, Err: err})
+			continue
+		}
+
+		v, opened := vaults[ref.Vault]
+		if !opened {
+			if err, failed := openErrs[ref.Vault]; failed {
+				problems = append(problems, ReferenceProblem{Name: name, Reference: raw, Err: err})
+				continue
+			}
+
+			v, err = w.OpenVault(ref.Vault)
+			if err != nil {
+				openErrs[ref.Vault] = err
+				problems = append(problems, ReferenceProblem{Name: name, Reference: raw, Err: err})
+				continue
+			}
+			vaults[ref.Vault] = v
+		}
+
+		exists, err := v.HasSecret(ref.Collection, ref.Secret)
+		if err == nil && !exists {
+			err = errs.New(error_codes.SecretNotFoundErrCode, "secret not found in vault").
+				WithContext("collection", ref.Collection).
+				WithContext("key", ref.Secret)
+		}
+		if err == nil && ref.Version > 0 {
+			exists, err = v.HasSecretVersion(ref.Collection, ref.Secret, ref.Version)
+			if err == nil && !exists {
+				err = errs.New(error_codes.SecretNotFoundErrCode, "secret version not found in vault").
+					WithContext("collection", ref.Collection).
+					WithContext("key", ref.Secret).
+					WithContext("version", ref.Version)
+			}
+		}
+		if err != nil {
+			problems = append(problems, ReferenceProblem{Name: name, Reference: raw, Err: err})
+		}
+	}
+
+	return problems
+}
+
+// ProjectReference is a project secret mapping that points into a linked vault
+type ProjectReference struct {
+	Project   string
+	Name      string
+	Reference *SecretReference
+}
+
+// ProjectReferences returns every project secret mapping in the workspace,
+// ordered by project and logical name. Mappings that fail to parse are skipped.
+func (w *Workspace) ProjectReferences() ([]ProjectReference, error) {
+	projects, err := w.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(projects)
+
+	var refs []ProjectReference
+	for _, name := range projects {
+		project, err := w.LoadProject(name)
+		if err != nil {
+			return nil, err
+		}
+
+		names := project.ListSecrets()
+		sort.Strings(names)
+		for _, logicalName := range names {
+			raw, _ := project.GetSecret(logicalName)
+
+			ref, err := ParseSecretReference(raw)
+			if err != nil {
+				continue
+			}
+
+			refs = append(refs, ProjectReference{Project: name, Name: logicalName, Reference: ref})
+		}
+	}
+
+	return refs, nil
+}
+
+// ReferencesTo returns the project secret mappings that point at the vault
+// linked under alias, limited to one collection when collection is not empty
+func (w *Workspace) ReferencesTo(alias, collection string) ([]ProjectReference, error) {
+	all, err := w.ProjectReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []ProjectReference
+	for _, r := range all {
+		if r.Reference.Vault != alias || (collection != "" && r.Reference.Collection != collection) {
+			continue
+		}
+		refs = append(refs, r)
+	}
+
+	return refs, nil
+}
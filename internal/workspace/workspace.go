@@ -11,6 +11,7 @@ import (
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/tomdoesdev/knox/internal/error_codes"
+	"github.com/tomdoesdev/knox/internal/vault"
 	"github.com/tomdoesdev/knox/internal/workspace/internal"
 	"github.com/tomdoesdev/knox/internal/workspace/internal/database"
 
@@ -32,9 +33,13 @@ type Workspace struct {
 
 // LinkedVault represents a vault linked to the workspace
 type LinkedVault struct {
-	Alias     string `json:"alias"`
-	Path      string `json:"path"`
-	CreatedAt string `json:"created_at"`
+	Alias string `json:"alias"`
+	// Path is the absolute path of the vault
+	Path string `json:"path"`
+	// StoredPath is the path as linked, which may be portable, see PortableVaultPath
+	StoredPath string `json:"stored_path"`
+	VaultID    string `json:"vault_id"`
+	CreatedAt  string `json:"created_at"`
 }
 
 // DataDir returns the full path to the workspace data directory
@@ -64,20 +69,27 @@ type LinkedProject struct {
 
 // FindWorkspace finds the nearest .knox directory, traversing up the directory tree until it finds it.
 func FindWorkspace(path string) (*Workspace, error) {
+	dir, err := FindWorkspaceDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return OpenWorkspace(dir)
+}
+
+// FindWorkspaceDir returns the nearest directory containing a .knox directory
+// without opening the workspace
+func FindWorkspaceDir(path string) (string, error) {
 	currentDir := path
 
 	for {
 		if internal.ContainsDataDirectory(currentDir) {
-			w, err := OpenWorkspace(currentDir)
-			if err != nil {
-				return nil, err
-			}
-			return w, nil
+			return currentDir, nil
 		}
 
 		parentDir := filepath.Dir(currentDir)
 		if parentDir == currentDir {
-			return nil, internal.ErrNoWorkspace
+			return "", internal.ErrNoWorkspace
 		}
 		currentDir = parentDir
 	}
@@ -170,7 +182,19 @@ func OpenWorkspace(path string) (*Workspace, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewWorkspace(db, workspaceRoot), nil
+
+	ws := NewWorkspace(db, workspaceRoot)
+	for _, p := range []PrivatePath{
+		{Kind: "workspace", Path: ws.DataDir(), Policy: fs.PrivateDir},
+		{Kind: "workspace database", Path: dbPath.String(), Policy: fs.PrivateFile},
+	} {
+		if err := ws.checkPermissions(p, false); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	return ws, nil
 }
 
 // ProjectsPath returns the path to the projects directory
@@ -178,6 +202,11 @@ func (w *Workspace) ProjectsPath() string {
 	return filepath.Join(w.path, internal.DataDirectoryName, internal.ProjectsDirectoryName)
 }
 
+// ProjectPath returns the path of the file holding the named project
+func (w *Workspace) ProjectPath(name string) string {
+	return filepath.Join(w.ProjectsPath(), name+".json")
+}
+
 // CreateProject creates a new project file
 func (w *Workspace) CreateProject(project *Project) error {
 	// Validate project structure
@@ -253,6 +282,21 @@ func (w *Workspace) UpdateProject(project *Project) error {
 		return err
 	}
 
+	return w.writeProject(project)
+}
+
+// RepairProject rewrites an existing project file without checking its
+// references against the linked vaults, so that projects which already
+// reference unlinked vaults can be repaired one secret at a time.
+func (w *Workspace) RepairProject(project *Project) error {
+	if err := project.Validate(); err != nil {
+		return err
+	}
+
+	return w.writeProject(project)
+}
+
+func (w *Workspace) writeProject(project *Project) error {
 	projectPath := filepath.Join(w.ProjectsPath(), project.Name+".json")
 
 	if !fs.IsFile(projectPath) {
@@ -272,7 +316,8 @@ func (w *Workspace) UpdateProject(project *Project) error {
 	return nil
 }
 
-// DeleteProject removes a project file
+// DeleteProject removes a project file. The current project cannot be
+// deleted, since current_project would be left naming a missing project.
 func (w *Workspace) DeleteProject(name string) error {
 	projectPath := filepath.Join(w.ProjectsPath(), name+".json")
 
@@ -280,7 +325,15 @@ func (w *Workspace) DeleteProject(name string) error {
 		return errs.New(error_codes.ProjectNotFoundErrCode, "project not found").WithContext("name", name)
 	}
 
-	err := os.Remove(projectPath)
+	current, err := w.GetConfig(CurrentProjectSetting)
+	if err != nil {
+		return err
+	}
+	if current == name {
+		return errs.New(error_codes.ValidationErrCode, "cannot delete the current project, use 'knox switch' to change it first").WithContext("name", name)
+	}
+
+	err = os.Remove(projectPath)
 	if err != nil {
 		return errs.Wrap(err, error_codes.FilePermissionErrCode, "failed to delete project file").WithContext("path", projectPath)
 	}
@@ -310,53 +363,46 @@ func (w *Workspace) ListProjects() ([]string, error) {
 
 // GetLinkedVaultAliases returns a list of all linked vault aliases
 func (w *Workspace) GetLinkedVaultAliases() ([]string, error) {
-	// TODO: Implement database query to get vault aliases
-	// For now, return empty slice - this will be implemented when we add vault linking
-	return []string{}, nil
-}
-
-// CurrentProject returns the currently active project name
-func (w *Workspace) CurrentProject() (string, error) {
-	return w.GetSetting("current_project")
-}
-
-// SetCurrentProject sets the currently active project
-func (w *Workspace) SetCurrentProject(projectName string) error {
-	return w.SetSetting("current_project", projectName)
-}
-
-// GetSetting retrieves a setting value from the workspace_settings table
-func (w *Workspace) GetSetting(key string) (string, error) {
-	query := "SELECT value FROM workspace_settings WHERE key = ? AND category = 'config'"
-
-	var value string
-	err := w.db.DB().QueryRow(query, key).Scan(&value)
+	rows, err := w.db.DB().Query("SELECT alias FROM linked_vaults ORDER BY alias")
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", errs.New(error_codes.SearchFailureErrCode, "setting not found").WithContext("key", key)
+		return nil, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to query linked vault aliases")
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	aliases := []string{}
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to scan vault alias")
 		}
-		return "", errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to get setting").WithContext("key", key)
+		aliases = append(aliases, alias)
 	}
 
-	return value, nil
-}
+	if err := rows.Err(); err != nil {
+		return nil, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "error iterating vault aliases")
+	}
 
-// SetSetting stores a setting value in the workspace_settings table
-func (w *Workspace) SetSetting(key, value string) error {
-	query := `
-		INSERT INTO workspace_settings (key, value, category, updated_at) 
-		VALUES (?, ?, 'config', CURRENT_TIMESTAMP)
-		ON CONFLICT(key) DO UPDATE SET 
-			value = excluded.value,
-			updated_at = CURRENT_TIMESTAMP
-	`
+	return aliases, nil
+}
 
-	_, err := w.db.DB().Exec(query, key, value)
+// CurrentProject returns the currently active project name
+func (w *Workspace) CurrentProject() (string, error) {
+	name, err := w.GetConfig(CurrentProjectSetting)
 	if err != nil {
-		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to set setting").WithContext("key", key).WithContext("value", value)
+		return "", err
+	}
+	if name == "" {
+		return "", errs.New(error_codes.SearchFailureErrCode, "no current project set")
 	}
 
-	return nil
+	return name, nil
+}
+
+// SetCurrentProject sets the currently active project
+func (w *Workspace) SetCurrentProject(projectName string) error {
+	return w.SetConfig(CurrentProjectSetting, projectName)
 }
 
 // GetMeta retrieves a metadata value from the workspace_settings table
@@ -393,11 +439,12 @@ func (w *Workspace) SetMeta(key, value string) error {
 	return nil
 }
 
-// LinkVault links a vault to the workspace with the given alias
+// LinkVault links a vault to the workspace with the given alias. vaultPath is
+// stored as given, so it may be absolute or in a portable form, see
+// PortableVaultPath.
 func (w *Workspace) LinkVault(alias, vaultPath string) error {
-	// Validate alias
-	if alias == "" {
-		return errs.New(error_codes.ValidationErrCode, "vault alias cannot be empty")
+	if err := vault.ValidateAlias(alias); err != nil {
+		return err
 	}
 
 	// Validate vault path
@@ -416,19 +463,19 @@ func (w *Workspace) LinkVault(alias, vaultPath string) error {
 		return errs.New(error_codes.ValidationErrCode, "vault alias already exists").WithContext("alias", alias)
 	}
 
-	// Check if path already exists
-	query = "SELECT COUNT(*) FROM linked_vaults WHERE path = ?"
-	err = w.db.DB().QueryRow(query, vaultPath).Scan(&count)
-	if err != nil {
-		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to check vault path").WithContext("path", vaultPath)
+	// Check if path already exists, in any of its stored forms
+	if err := w.expectPathNotLinked(vaultPath, ""); err != nil {
+		return err
 	}
-	if count > 0 {
-		return errs.New(error_codes.ValidationErrCode, "vault path already linked").WithContext("path", vaultPath)
+
+	vaultID, err := vault.ReadID(w.ExpandVaultPath(vaultPath))
+	if err != nil {
+		return errs.Wrap(err, error_codes.VaultConnectionErrCode, "failed to read vault id").WithContext("path", vaultPath)
 	}
 
 	// Insert the vault link
-	insertQuery := "INSERT INTO linked_vaults (alias, path) VALUES (?, ?)"
-	_, err = w.db.DB().Exec(insertQuery, alias, vaultPath)
+	insertQuery := "INSERT INTO linked_vaults (alias, path, vault_id) VALUES (?, ?, ?)"
+	_, err = w.db.DB().Exec(insertQuery, alias, vaultPath, vaultID)
 	if err != nil {
 		return errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to link vault").WithContext("alias", alias).WithContext("path", vaultPath)
 	}
@@ -438,7 +485,7 @@ func (w *Workspace) LinkVault(alias, vaultPath string) error {
 
 // GetLinkedVaults returns all linked vaults
 func (w *Workspace) GetLinkedVaults() ([]LinkedVault, error) {
-	query := "SELECT alias, path, created_at FROM linked_vaults ORDER BY alias"
+	query := "SELECT alias, path, vault_id, created_at FROM linked_vaults ORDER BY alias"
 
 	rows, err := w.db.DB().Query(query)
 	if err != nil {
@@ -451,10 +498,11 @@ func (w *Workspace) GetLinkedVaults() ([]LinkedVault, error) {
 	var vaults []LinkedVault
 	for rows.Next() {
 		var vault LinkedVault
-		err := rows.Scan(&vault.Alias, &vault.Path, &vault.CreatedAt)
+		err := rows.Scan(&vault.Alias, &vault.StoredPath, &vault.VaultID, &vault.CreatedAt)
 		if err != nil {
 			return nil, errs.Wrap(err, error_codes.DatabaseFailureErrCode, "failed to scan vault row")
 		}
+		vault.Path = w.ExpandVaultPath(vault.StoredPath)
 		vaults = append(vaults, vault)
 	}
 
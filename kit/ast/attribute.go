@@ -0,0 +1,180 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Attribute wraps a node attribute value with typed accessors. Accessors
+// convert between compatible types, e.g. the string "true" is a valid bool
+// and the int 1 is true.
+type Attribute struct {
+	value any
+}
+
+func NewAttribute(value any) Attribute {
+	if attr, ok := value.(Attribute); ok {
+		return attr
+	}
+	return Attribute{value: value}
+}
+
+func StringAttr(value string) Attribute {
+	return Attribute{value: value}
+}
+
+func BoolAttr(value bool) Attribute {
+	return Attribute{value: value}
+}
+
+func IntAttr(value int) Attribute {
+	return Attribute{value: value}
+}
+
+func FloatAttr(value float64) Attribute {
+	return Attribute{value: value}
+}
+
+// Value returns the raw wrapped value
+func (a Attribute) Value() any {
+	return a.value
+}
+
+func (a Attribute) AsString() (string, error) {
+	switch v := a.value.(type) {
+	case string:
+		return v, nil
+	case fmt.Stringer:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v), nil
+	case float32, float64:
+		return fmt.Sprintf("%g", v), nil
+	default:
+		return "", conversionError(a.value, "string")
+	}
+}
+
+func (a Attribute) AsBool() (bool, error) {
+	switch v := a.value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	case int, int8, int16, int32, int64:
+		return reflect.ValueOf(v).Int() != 0, nil
+	case uint, uint8, uint16, uint32, uint64:
+		return reflect.ValueOf(v).Uint() != 0, nil
+	default:
+		return false, conversionError(a.value, "bool")
+	}
+}
+
+func (a Attribute) AsInt() (int, error) {
+	switch v := a.value.(type) {
+	case int:
+		return v, nil
+	case int8, int16, int32, int64:
+		return int(reflect.ValueOf(v).Int()), nil
+	case uint, uint8, uint16, uint32, uint64:
+		return int(reflect.ValueOf(v).Uint()), nil
+	case float32, float64:
+		return int(reflect.ValueOf(v).Float()), nil
+	case string:
+		return strconv.Atoi(v)
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		return 0, conversionError(a.value, "int")
+	}
+}
+
+func (a Attribute) AsFloat() (float64, error) {
+	switch v := a.value.(type) {
+	case float32, float64:
+		return reflect.ValueOf(v).Float(), nil
+	case int, int8, int16, int32, int64:
+		return float64(reflect.ValueOf(v).Int()), nil
+	case uint, uint8, uint16, uint32, uint64:
+		return float64(reflect.ValueOf(v).Uint()), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, conversionError(a.value, "float")
+	}
+}
+
+func (a Attribute) AsStringOr(defaultValue string) string {
+	if v, err := a.AsString(); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+func (a Attribute) AsBoolOr(defaultValue bool) bool {
+	if v, err := a.AsBool(); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+func (a Attribute) AsIntOr(defaultValue int) int {
+	if v, err := a.AsInt(); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+func (a Attribute) AsFloatOr(defaultValue float64) float64 {
+	if v, err := a.AsFloat(); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+func (a Attribute) IsString() bool {
+	_, ok := a.value.(string)
+	return ok
+}
+
+func (a Attribute) IsBool() bool {
+	_, ok := a.value.(bool)
+	return ok
+}
+
+func (a Attribute) IsInt() bool {
+	switch a.value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a Attribute) IsFloat() bool {
+	switch a.value.(type) {
+	case float32, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// String renders the attribute for display. Values without a string form
+// fall back to fmt formatting.
+func (a Attribute) String() string {
+	if v, err := a.AsString(); err == nil {
+		return v
+	}
+	return fmt.Sprint(a.value)
+}
+
+func conversionError(value any, target string) error {
+	return fmt.Errorf("cannot convert %T to %s", value, target)
+}
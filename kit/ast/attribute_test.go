@@ -0,0 +1,109 @@
+package ast
+
+import "testing"
+
+func TestAttribute_AsBool(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "bool", value: true, want: true},
+		{name: "string", value: "true", want: true},
+		{name: "int zero", value: 0, want: false},
+		{name: "int non-zero", value: 1, want: true},
+		{name: "invalid string", value: "yes please", wantErr: true},
+		{name: "unsupported type", value: []string{"x"}, wantErr: true},
+		{name: "nil", value: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAttribute(tt.value).AsBool()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AsBool() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("AsBool() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttribute_AsInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    int
+		wantErr bool
+	}{
+		{name: "int", value: 42, want: 42},
+		{name: "int64", value: int64(7), want: 7},
+		{name: "float", value: 3.9, want: 3},
+		{name: "string", value: "42", want: 42},
+		{name: "bool", value: true, want: 1},
+		{name: "invalid string", value: "not-a-number", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAttribute(tt.value).AsInt()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AsInt() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("AsInt() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttribute_AsString(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    string
+		wantErr bool
+	}{
+		{name: "string", value: "hello", want: "hello"},
+		{name: "stringer", value: StringValue("content"), want: "content"},
+		{name: "bool", value: false, want: "false"},
+		{name: "int", value: 12, want: "12"},
+		{name: "float", value: 1.5, want: "1.5"},
+		{name: "unsupported type", value: struct{}{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAttribute(tt.value).AsString()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AsString() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("AsString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttribute_Fallbacks(t *testing.T) {
+	var missing Attribute
+
+	if got := missing.AsStringOr("untitled"); got != "untitled" {
+		t.Errorf("AsStringOr() = %q, want %q", got, "untitled")
+	}
+	if got := missing.AsBoolOr(true); !got {
+		t.Error("AsBoolOr() = false, want true")
+	}
+	if got := NewAttribute("abc").AsIntOr(8080); got != 8080 {
+		t.Errorf("AsIntOr() = %d, want 8080", got)
+	}
+
+	if !IntAttr(1).IsInt() || IntAttr(1).IsString() {
+		t.Error("IntAttr() type checks are wrong")
+	}
+	if NewAttribute(BoolAttr(true)).Value() != true {
+		t.Error("NewAttribute() should not double wrap an Attribute")
+	}
+}
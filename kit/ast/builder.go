@@ -0,0 +1,85 @@
+package ast
+
+// Builder builds a tree of nodes with a chainable API. Every method operates
+// on the current node; Node descends into a new child and Up returns to its
+// parent, so the shape of the calling code mirrors the shape of the tree.
+//
+//	tree := ast.NewBuilder("list").
+//		Node("item").Content("first").Up().
+//		Node("item").Content("second").Up().
+//		Build()
+type Builder interface {
+	// Node adds a child to the current node and makes it current
+	Node(nodeType string) Builder
+	// Up makes the parent of the current node current. It is a no-op at the root.
+	Up() Builder
+	// Root makes the root node current
+	Root() Builder
+
+	// Content sets the content of the current node
+	Content(content string) Builder
+	// Attr sets an attribute on the current node
+	Attr(key string, value any) Builder
+
+	// Build returns the root of the tree
+	Build() Node
+}
+
+type nodeBuilder struct {
+	root    *BasicNode
+	current *BasicNode
+	stack   []*BasicNode
+}
+
+// NewBuilder starts a new tree with a root node of rootType
+func NewBuilder(rootType string) Builder {
+	root := NewContainerNode(rootType)
+
+	return &nodeBuilder{
+		root:    root,
+		current: root,
+		stack:   make([]*BasicNode, 0),
+	}
+}
+
+func (b *nodeBuilder) Node(nodeType string) Builder {
+	child := NewContainerNode(nodeType)
+	b.current.AddChild(child)
+
+	b.stack = append(b.stack, b.current)
+	b.current = child
+
+	return b
+}
+
+func (b *nodeBuilder) Up() Builder {
+	if len(b.stack) == 0 {
+		return b
+	}
+
+	b.current = b.stack[len(b.stack)-1]
+	b.stack = b.stack[:len(b.stack)-1]
+
+	return b
+}
+
+func (b *nodeBuilder) Root() Builder {
+	b.current = b.root
+	b.stack = b.stack[:0]
+
+	return b
+}
+
+func (b *nodeBuilder) Content(content string) Builder {
+	b.current.SetContent(StringValue(content))
+	return b
+}
+
+func (b *nodeBuilder) Attr(key string, value any) Builder {
+	b.current.SetAttribute(key, value)
+	return b
+}
+
+func (b *nodeBuilder) Build() Node {
+	return b.root
+}
@@ -0,0 +1,87 @@
+package ast
+
+import "testing"
+
+func TestBuilder_SimpleTree(t *testing.T) {
+	tree := NewBuilder("root").
+		Content("root content").
+		Node("child").
+		Content("child content").
+		Node("grandchild").
+		Content("leaf content").
+		Up().
+		Up().
+		Build()
+
+	if tree.Type() != "root" || tree.Content().String() != "root content" {
+		t.Fatalf("root = %s %q, want root %q", tree.Type(), tree.Content(), "root content")
+	}
+	if len(tree.Children()) != 1 {
+		t.Fatalf("root has %d children, want 1", len(tree.Children()))
+	}
+
+	child := tree.Children()[0]
+	if child.Type() != "child" || child.Content().String() != "child content" {
+		t.Errorf("child = %s %q", child.Type(), child.Content())
+	}
+	if len(child.Children()) != 1 || child.Children()[0].Content().String() != "leaf content" {
+		t.Errorf("grandchild not attached to child")
+	}
+}
+
+func TestBuilder_Navigation(t *testing.T) {
+	tree := NewBuilder("root").
+		Node("a").
+		Node("b").
+		Node("c").Up().
+		Up().
+		Up().
+		Node("d").Up().
+		Up().Up().
+		Node("e").
+		Root().
+		Node("f").
+		Build()
+
+	var types []string
+	for _, child := range tree.Children() {
+		types = append(types, child.Type())
+	}
+
+	want := []string{"a", "d", "e", "f"}
+	if len(types) != len(want) {
+		t.Fatalf("root children = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("root children = %v, want %v", types, want)
+			break
+		}
+	}
+
+	if tree.Children()[1].Content().String() != "" {
+		t.Errorf("container content = %q, want empty", tree.Children()[1].Content())
+	}
+}
+
+func TestBuilder_Attributes(t *testing.T) {
+	tree := NewBuilder("config").
+		Attr("enabled", true).
+		Attr("port", 8080).
+		Attr("name", "myapp").
+		Build()
+
+	attr, ok := tree.GetAttribute("enabled")
+	if !ok || !attr.AsBoolOr(false) {
+		t.Errorf("enabled attribute = %v, %t", attr.Value(), ok)
+	}
+	if attr, _ := tree.GetAttribute("port"); attr.AsIntOr(0) != 8080 {
+		t.Errorf("port attribute = %v, want 8080", attr.Value())
+	}
+	if _, ok := tree.GetAttribute("missing"); ok {
+		t.Error("missing attribute reported as set")
+	}
+	if len(tree.Attributes()) != 3 {
+		t.Errorf("Attributes() has %d entries, want 3", len(tree.Attributes()))
+	}
+}
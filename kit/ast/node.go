@@ -0,0 +1,126 @@
+// Package ast provides a generic tree of typed nodes used to describe
+// structured output independently of how it is rendered.
+package ast
+
+import "fmt"
+
+// Node represents a node in an abstract syntax tree
+type Node interface {
+	// Type returns a string identifier for the node type
+	Type() string
+
+	// Content returns the primary content of this node. Container-only nodes
+	// return EmptyValue.
+	Content() fmt.Stringer
+
+	// Children returns all child nodes in insertion order
+	Children() []Node
+
+	// Attributes returns the metadata associated with this node
+	Attributes() map[string]Attribute
+
+	// GetAttribute returns a single attribute and whether it was set
+	GetAttribute(key string) (Attribute, bool)
+}
+
+// MutableNode is a Node that can be modified after creation
+type MutableNode interface {
+	Node
+
+	SetContent(content fmt.Stringer)
+	AddChild(child Node)
+
+	// SetAttribute wraps value in an Attribute and stores it under key
+	SetAttribute(key string, value any)
+	SetAttr(key string, attr Attribute)
+}
+
+// StringValue is node content backed by a plain string
+type StringValue string
+
+func (s StringValue) String() string {
+	return string(s)
+}
+
+// EmptyValue is the content of nodes that only hold children
+type EmptyValue struct{}
+
+func (EmptyValue) String() string {
+	return ""
+}
+
+// BasicNode is the default MutableNode implementation
+type BasicNode struct {
+	nodeType   string
+	content    fmt.Stringer
+	children   []Node
+	attributes map[string]Attribute
+}
+
+// NewNode creates a node of the given type with content. A nil content is
+// stored as EmptyValue.
+func NewNode(nodeType string, content fmt.Stringer) *BasicNode {
+	if content == nil {
+		content = EmptyValue{}
+	}
+
+	return &BasicNode{
+		nodeType:   nodeType,
+		content:    content,
+		children:   make([]Node, 0),
+		attributes: make(map[string]Attribute),
+	}
+}
+
+// NewTextNode creates a "text" node holding content
+func NewTextNode(content string) *BasicNode {
+	return NewNode("text", StringValue(content))
+}
+
+// NewContainerNode creates a node without content
+func NewContainerNode(nodeType string) *BasicNode {
+	return NewNode(nodeType, EmptyValue{})
+}
+
+func (n *BasicNode) Type() string {
+	return n.nodeType
+}
+
+func (n *BasicNode) Content() fmt.Stringer {
+	return n.content
+}
+
+func (n *BasicNode) Children() []Node {
+	return n.children
+}
+
+func (n *BasicNode) Attributes() map[string]Attribute {
+	return n.attributes
+}
+
+func (n *BasicNode) GetAttribute(key string) (Attribute, bool) {
+	attr, ok := n.attributes[key]
+	return attr, ok
+}
+
+func (n *BasicNode) SetContent(content fmt.Stringer) {
+	if content == nil {
+		content = EmptyValue{}
+	}
+	n.content = content
+}
+
+func (n *BasicNode) AddChild(child Node) {
+	if child == nil {
+		return
+	}
+	n.children = append(n.children, child)
+}
+
+func (n *BasicNode) SetAttribute(key string, value any) {
+	n.attributes[key] = NewAttribute(value)
+}
+
+func (n *BasicNode) SetAttr(key string, attr Attribute) {
+	n.attributes[key] = attr
+}
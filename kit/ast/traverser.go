@@ -0,0 +1,68 @@
+package ast
+
+// VisitorFunc is called for each node during traversal. Returning an error
+// stops the traversal and the error is returned to the caller.
+type VisitorFunc func(node Node) error
+
+// WithPreOrderTraversal visits each node before its children, depth first
+func WithPreOrderTraversal(root Node, visit VisitorFunc) error {
+	if root == nil || visit == nil {
+		return nil
+	}
+
+	return preOrderTraversal(root, visit)
+}
+
+func preOrderTraversal(node Node, visit VisitorFunc) error {
+	if err := visit(node); err != nil {
+		return err
+	}
+
+	for _, child := range node.Children() {
+		if err := preOrderTraversal(child, visit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WithPostOrderTraversal visits each node after its children, depth first
+func WithPostOrderTraversal(root Node, visit VisitorFunc) error {
+	if root == nil || visit == nil {
+		return nil
+	}
+
+	return postOrderTraversal(root, visit)
+}
+
+func postOrderTraversal(node Node, visit VisitorFunc) error {
+	for _, child := range node.Children() {
+		if err := postOrderTraversal(child, visit); err != nil {
+			return err
+		}
+	}
+
+	return visit(node)
+}
+
+// WithBreadthFirstTraversal visits nodes level by level, left to right
+func WithBreadthFirstTraversal(root Node, visit VisitorFunc) error {
+	if root == nil || visit == nil {
+		return nil
+	}
+
+	queue := []Node{root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if err := visit(current); err != nil {
+			return err
+		}
+
+		queue = append(queue, current.Children()...)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package ast
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testTree builds
+//
+//	a
+//	├── b
+//	│   ├── d
+//	│   └── e
+//	└── c
+func testTree() Node {
+	return NewBuilder("a").
+		Node("b").
+		Node("d").Up().
+		Node("e").Up().
+		Up().
+		Node("c").
+		Build()
+}
+
+func visitOrder(t *testing.T, traverse func(Node, VisitorFunc) error) string {
+	t.Helper()
+
+	var order []string
+	err := traverse(testTree(), func(node Node) error {
+		order = append(order, node.Type())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("traversal error = %v", err)
+	}
+
+	return strings.Join(order, "")
+}
+
+func TestTraversalOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		traverse func(Node, VisitorFunc) error
+		want     string
+	}{
+		{name: "pre-order", traverse: WithPreOrderTraversal, want: "abdec"},
+		{name: "post-order", traverse: WithPostOrderTraversal, want: "debca"},
+		{name: "breadth-first", traverse: WithBreadthFirstTraversal, want: "abcde"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := visitOrder(t, tt.traverse); got != tt.want {
+				t.Errorf("visit order = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraversal_StopsOnError(t *testing.T) {
+	stop := errors.New("stop")
+
+	var visited int
+	err := WithPreOrderTraversal(testTree(), func(node Node) error {
+		visited++
+		if node.Type() == "d" {
+			return stop
+		}
+		return nil
+	})
+
+	if !errors.Is(err, stop) {
+		t.Fatalf("error = %v, want %v", err, stop)
+	}
+	if visited != 3 {
+		t.Errorf("visited %d nodes, want 3", visited)
+	}
+}
+
+func TestTraversal_NilRoot(t *testing.T) {
+	err := WithBreadthFirstTraversal(nil, func(node Node) error {
+		t.Error("visitor called for nil root")
+		return nil
+	})
+	if err != nil {
+		t.Errorf("error = %v, want nil", err)
+	}
+}
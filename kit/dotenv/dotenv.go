@@ -0,0 +1,244 @@
+package dotenv
+
+import (
+	"io"
+	"strings"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+var (
+	SyntaxErrCode errs.Code = "DOTENV_SYNTAX"
+	ReadErrCode   errs.Code = "DOTENV_READ"
+)
+
+// Entry is a single KEY=VALUE assignment read from a dotenv file
+type Entry struct {
+	Key   string
+	Value string
+	// Line is the line the assignment starts on
+	Line int
+}
+
+// Parse reads KEY=VALUE assignments from r in file order.
+//
+// Blank lines and lines starting with '#' are ignored and an optional
+// "export " prefix is accepted. Single quoted values are taken literally.
+// Double quoted values have \n, \r, \t, \", \\ and \$ expanded, while other
+// escapes are kept as written. Quoted values may span several lines and may be
+// followed by a comment. Unquoted values end at the end of the line or at the
+// first '#' preceded by whitespace.
+func Parse(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errs.Wrap(err, ReadErrCode, "dotenv: read failed")
+	}
+
+	p := &parser{src: strings.ReplaceAll(string(data), "\r\n", "\n"), line: 1}
+
+	var entries []Entry
+	for {
+		p.skipBlank()
+		if p.done() {
+			break
+		}
+
+		line := p.line
+		entry, err := p.entry()
+		if err != nil {
+			return nil, errs.Wrap(err, SyntaxErrCode, "dotenv: invalid line").WithContext("line", line)
+		}
+		entry.Line = line
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// ParseString parses dotenv content held in a string
+func ParseString(s string) ([]Entry, error) {
+	return Parse(strings.NewReader(s))
+}
+
+// ToMap collapses entries into a map, later assignments winning
+func ToMap(entries []Entry) map[string]string {
+	values := make(map[string]string, len(entries))
+	for _, e := range entries {
+		values[e.Key] = e.Value
+	}
+	return values
+}
+
+// parser walks dotenv source one assignment at a time, tracking the line
+// number for error reporting
+type parser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *parser) next() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipBlank skips whitespace, empty lines and comment lines
+func (p *parser) skipBlank() {
+	for !p.done() {
+		switch p.peek() {
+		case ' ', '\t', '\n':
+			p.next()
+		case '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+// skipSpaces skips spaces and tabs without leaving the current line
+func (p *parser) skipSpaces() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.next()
+	}
+}
+
+// skipLine skips to the start of the next line
+func (p *parser) skipLine() {
+	for !p.done() && p.next() != '\n' {
+	}
+}
+
+// restOfLine returns the remainder of the current line and moves past it
+func (p *parser) restOfLine() string {
+	start := p.pos
+	for !p.done() && p.peek() != '\n' {
+		p.next()
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *parser) entry() (Entry, error) {
+	key := p.key()
+	if key == "export" && !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipSpaces()
+		key = p.key()
+	}
+
+	if key == "" {
+		return Entry{}, errs.New(SyntaxErrCode, "expected KEY=VALUE").WithContext("text", strings.TrimSpace(p.restOfLine()))
+	}
+
+	p.skipSpaces()
+	if p.done() || p.peek() != '=' {
+		return Entry{}, errs.New(SyntaxErrCode, "expected '=' after key").WithContext("key", key)
+	}
+	p.next()
+	p.skipSpaces()
+
+	value, err := p.value()
+	if err != nil {
+		return Entry{}, errs.Wrap(err, SyntaxErrCode, "invalid value").WithContext("key", key)
+	}
+
+	return Entry{Key: key, Value: value}, nil
+}
+
+// key reads a key made of letters, digits, '_', '.' and '-'
+func (p *parser) key() string {
+	start := p.pos
+	for !p.done() && isKeyChar(p.peek()) {
+		p.next()
+	}
+	return p.src[start:p.pos]
+}
+
+func isKeyChar(c byte) bool {
+	return c == '_' || c == '.' || c == '-' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func (p *parser) value() (string, error) {
+	if p.done() || p.peek() == '\n' {
+		return "", nil
+	}
+
+	switch p.peek() {
+	case '\'':
+		return p.quoted('\'', false)
+	case '"':
+		return p.quoted('"', true)
+	default:
+		return unquoted(p.restOfLine()), nil
+	}
+}
+
+// quoted reads a value enclosed in quote, which may span several lines, and
+// checks that nothing but a comment follows it on its closing line
+func (p *parser) quoted(quote byte, escapes bool) (string, error) {
+	p.next()
+
+	var b strings.Builder
+	closed := false
+	for !p.done() {
+		c := p.next()
+		if c == quote {
+			closed = true
+			break
+		}
+		if c == '\\' && escapes && !p.done() {
+			b.WriteString(unescape(p.next()))
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	if !closed {
+		return "", errs.New(SyntaxErrCode, "unterminated quoted value")
+	}
+
+	rest := strings.TrimSpace(p.restOfLine())
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", errs.New(SyntaxErrCode, "unexpected text after quoted value").WithContext("text", rest)
+	}
+
+	return b.String(), nil
+}
+
+func unescape(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case '"', '\\', '$':
+		return string(c)
+	default:
+		return "\\" + string(c)
+	}
+}
+
+// unquoted trims an unquoted value and strips a trailing comment
+func unquoted(raw string) string {
+	for i := 1; i < len(raw); i++ {
+		if raw[i] == '#' && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+			raw = raw[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(raw)
+}
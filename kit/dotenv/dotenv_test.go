@@ -0,0 +1,144 @@
+package dotenv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+func TestParseString(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Entry
+	}{
+		{
+			name:  "simple assignments",
+			input: "A=1\nB=two\n",
+			want:  []Entry{{Key: "A", Value: "1", Line: 1}, {Key: "B", Value: "two", Line: 2}},
+		},
+		{
+			name:  "comments and blank lines",
+			input: "# header\n\nA=1 # trailing\n  # indented\nB=x#y\n",
+			want:  []Entry{{Key: "A", Value: "1", Line: 3}, {Key: "B", Value: "x#y", Line: 5}},
+		},
+		{
+			name:  "export prefix",
+			input: "export A=1\nexport\tB = 2\nexport=3\n",
+			want: []Entry{
+				{Key: "A", Value: "1", Line: 1},
+				{Key: "B", Value: "2", Line: 2},
+				{Key: "export", Value: "3", Line: 3},
+			},
+		},
+		{
+			name:  "empty values",
+			input: "A=\nB=''\nC=\"\"\n",
+			want:  []Entry{{Key: "A", Value: "", Line: 1}, {Key: "B", Value: "", Line: 2}, {Key: "C", Value: "", Line: 3}},
+		},
+		{
+			name:  "single quotes are literal",
+			input: `A='a \n "b" # c' # comment`,
+			want:  []Entry{{Key: "A", Value: `a \n "b" # c`, Line: 1}},
+		},
+		{
+			name:  "double quote escapes",
+			input: `A="tab\there \"q\" \\ \$HOME \x"`,
+			want:  []Entry{{Key: "A", Value: "tab\there \"q\" \\ $HOME \\x", Line: 1}},
+		},
+		{
+			name:  "multi-line values",
+			input: "KEY=\"-----BEGIN-----\nabc\n-----END-----\"\nNEXT='x\ny'\nLAST=z\n",
+			want: []Entry{
+				{Key: "KEY", Value: "-----BEGIN-----\nabc\n-----END-----", Line: 1},
+				{Key: "NEXT", Value: "x\ny", Line: 4},
+				{Key: "LAST", Value: "z", Line: 6},
+			},
+		},
+		{
+			name:  "windows line endings",
+			input: "A=1\r\nB=\"2\"\r\n",
+			want:  []Entry{{Key: "A", Value: "1", Line: 1}, {Key: "B", Value: "2", Line: 2}},
+		},
+		{
+			name:  "later assignments are kept in order",
+			input: "A=1\nA=2\n",
+			want:  []Entry{{Key: "A", Value: "1", Line: 1}, {Key: "A", Value: "2", Line: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseString(tt.input)
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseString() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseString_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantLine int
+	}{
+		{name: "missing equals", input: "A=1\nB\n", wantLine: 2},
+		{name: "space in key", input: "A B=1\n", wantLine: 1},
+		{name: "unterminated double quote", input: "A=1\nB=\"open\nC=2\n", wantLine: 2},
+		{name: "unterminated single quote", input: "A='open\n", wantLine: 1},
+		{name: "text after quoted value", input: "A=\"x\" y\n", wantLine: 1},
+		{name: "no key", input: "=1\n", wantLine: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseString(tt.input)
+			if err == nil {
+				t.Fatal("ParseString() expected error")
+			}
+			if !errs.Is(err, SyntaxErrCode) {
+				t.Errorf("ParseString() error = %v, want %s", err, SyntaxErrCode)
+			}
+
+			var e *errs.Error
+			if !errors.As(err, &e) {
+				t.Fatalf("ParseString() error is not an *errs.Error: %v", err)
+			}
+			if line := e.Context["line"]; line != tt.wantLine {
+				t.Errorf("ParseString() error line = %v, want %d", line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestToMap(t *testing.T) {
+	got := ToMap([]Entry{{Key: "A", Value: "1"}, {Key: "B", Value: "2"}, {Key: "A", Value: "3"}})
+	want := map[string]string{"A": "3", "B": "2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToMap() = %v, want %v", got, want)
+	}
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	values := map[string]string{
+		"PLAIN":     "value",
+		"EMPTY":     "",
+		"QUOTES":    `it's "quoted"`,
+		"MULTILINE": "line one\nline two\r\n\tindented",
+		"SHELLISH":  `$HOME \n # not a comment \\`,
+	}
+
+	got, err := ParseString(string(Marshal(values)))
+	if err != nil {
+		t.Fatalf("ParseString(Marshal()) error = %v", err)
+	}
+	if !reflect.DeepEqual(ToMap(got), values) {
+		t.Errorf("ParseString(Marshal()) = %v, want %v", ToMap(got), values)
+	}
+}
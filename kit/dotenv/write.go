@@ -0,0 +1,50 @@
+package dotenv
+
+import (
+	"sort"
+	"strings"
+)
+
+// Marshal formats values as KEY="VALUE" lines ordered by key. Values are
+// double quoted and escaped so that Parse reads them back unchanged.
+func Marshal(values map[string]string) []byte {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(Quote(values[key]))
+		b.WriteByte('\n')
+	}
+
+	return []byte(b.String())
+}
+
+// Quote double quotes value, escaping the characters that Parse expands
+func Quote(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '"', '\\', '$':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+
+	return b.String()
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -105,3 +106,46 @@ func WriteFile(path string, data []byte, perm os.FileMode) error {
 	}
 	return nil
 }
+
+// WriteFileAtomic writes data to a temporary file in the same directory as
+// path and renames it into place, so readers never see a partially written
+// file. The file is created with perm from the start and keeps it even when
+// it replaces an existing file.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if path == "" {
+		return fmt.Errorf("fs: cannot write file with empty path")
+	}
+
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+name+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("fs: create temp file for %q: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("fs: chmod temp file for %q: %w", path, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("fs: write temp file for %q: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("fs: sync temp file for %q: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("fs: close temp file for %q: %w", path, err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("fs: move temp file to %q: %w", path, err)
+	}
+	return nil
+}
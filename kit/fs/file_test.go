@@ -0,0 +1,72 @@
+//go:build unix
+
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing bool
+		perm     os.FileMode
+	}{
+		{name: "new file", perm: 0600},
+		{name: "replaces existing file", existing: true, perm: 0600},
+		{name: "keeps perm over existing mode", existing: true, perm: 0640},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "out.env")
+			if tt.existing {
+				if err := os.WriteFile(path, []byte("old contents that are longer"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := WriteFileAtomic(path, []byte("A=1\n"), tt.perm); err != nil {
+				t.Fatalf("WriteFileAtomic() error = %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "A=1\n" {
+				t.Errorf("contents = %q, want %q", data, "A=1\n")
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.Mode().Perm(); got != tt.perm {
+				t.Errorf("mode = %o, want %o", got, tt.perm)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("directory holds %d entries, want only the written file", len(entries))
+			}
+		})
+	}
+}
+
+func TestWriteFileAtomic_Errors(t *testing.T) {
+	if err := WriteFileAtomic("", []byte("x"), 0600); err == nil {
+		t.Error("WriteFileAtomic() with an empty path succeeded")
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing", "out.env")
+	if err := WriteFileAtomic(missing, []byte("x"), 0600); err == nil {
+		t.Error("WriteFileAtomic() into a missing directory succeeded")
+	}
+}
@@ -0,0 +1,93 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+var (
+	ChmodFailedErrCode  errs.Code = "CHMOD"
+	ForeignOwnerErrCode errs.Code = "FOREIGN_OWNER"
+)
+
+// Policy is the most permissive mode a file or directory may have. Paths must
+// also be owned by the current user.
+type Policy struct {
+	Mode os.FileMode
+}
+
+var (
+	// PrivateFile allows only the owner to read and write
+	PrivateFile = Policy{Mode: 0600}
+	// PrivateDir allows only the owner to list, enter and change
+	PrivateDir = Policy{Mode: 0700}
+)
+
+// Violation describes how a path breaks a Policy
+type Violation struct {
+	Path string
+	// Mode is the path's permission bits, Excess the bits the policy does not allow
+	Mode   os.FileMode
+	Excess os.FileMode
+	// UID owns the path. Foreign is set when that is not the current user.
+	UID     int
+	Foreign bool
+}
+
+func (v *Violation) String() string {
+	if v.Foreign {
+		return fmt.Sprintf("%s is owned by uid %d, not the current user", v.Path, v.UID)
+	}
+	return fmt.Sprintf("%s is accessible by other users (mode %04o)", v.Path, v.Mode)
+}
+
+// Check reports how path breaks p, or nil when it complies. On systems
+// without Unix permissions every path complies.
+func (p Policy) Check(path string) (*Violation, error) {
+	if !permissionsSupported {
+		return nil, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	perm := info.Mode().Perm()
+	v := &Violation{Path: path, Mode: perm, Excess: perm &^ p.Mode}
+	if uid, ok := fileOwner(info); ok {
+		v.UID = uid
+		v.Foreign = uid != os.Getuid()
+	}
+
+	if v.Excess == 0 && !v.Foreign {
+		return nil, nil
+	}
+	return v, nil
+}
+
+// Enforce removes the bits p does not allow from path and reports whether it
+// changed anything. Ownership cannot be changed, so a path owned by another
+// user is an error.
+func (p Policy) Enforce(path string) (bool, error) {
+	v, err := p.Check(path)
+	if err != nil || v == nil {
+		return false, err
+	}
+
+	if v.Foreign {
+		return false, errs.New(ForeignOwnerErrCode, "fs: path is owned by another user").
+			WithPath(path).
+			WithContext("uid", v.UID)
+	}
+
+	if err := os.Chmod(path, v.Mode&^v.Excess); err != nil {
+		return false, errs.Wrap(err, ChmodFailedErrCode, "fs: change permissions failed").
+			WithPath(path).
+			WithContext("perm", v.Mode&^v.Excess)
+	}
+
+	return true, nil
+}
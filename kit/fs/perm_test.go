@@ -0,0 +1,87 @@
+//go:build unix
+
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       os.FileMode
+		wantExcess os.FileMode
+	}{
+		{name: "private", mode: 0600, wantExcess: 0},
+		{name: "stricter than policy", mode: 0400, wantExcess: 0},
+		{name: "group readable", mode: 0640, wantExcess: 0040},
+		{name: "world readable", mode: 0644, wantExcess: 0044},
+		{name: "owner executable", mode: 0700, wantExcess: 0100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			if err := os.WriteFile(path, nil, 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(path, tt.mode); err != nil {
+				t.Fatal(err)
+			}
+
+			v, err := PrivateFile.Check(path)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			if tt.wantExcess == 0 {
+				if v != nil {
+					t.Errorf("Check() = %v, want nil", v)
+				}
+				return
+			}
+			if v == nil {
+				t.Fatalf("Check() = nil, want excess %04o", tt.wantExcess)
+			}
+			if v.Excess != tt.wantExcess {
+				t.Errorf("Check() excess = %04o, want %04o", v.Excess, tt.wantExcess)
+			}
+		})
+	}
+}
+
+func TestPolicy_Enforce(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dir")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := PrivateDir.Enforce(dir)
+	if err != nil || !changed {
+		t.Fatalf("Enforce() = %v, %v, want true, nil", changed, err)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("mode after Enforce() = %04o, want 0700", perm)
+	}
+
+	changed, err = PrivateDir.Enforce(dir)
+	if err != nil || changed {
+		t.Errorf("second Enforce() = %v, %v, want false, nil", changed, err)
+	}
+}
+
+func TestPolicy_CheckMissing(t *testing.T) {
+	if _, err := PrivateFile.Check(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("Check() error = %v, want not exist", err)
+	}
+}
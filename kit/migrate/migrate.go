@@ -0,0 +1,147 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+var (
+	InvalidMigrationErrCode errs.Code = "MIGRATION_INVALID"
+	MigrationFailedErrCode  errs.Code = "MIGRATION_FAILED"
+	SchemaTooNewErrCode     errs.Code = "SCHEMA_TOO_NEW"
+)
+
+// Migration is a single, ordered up-migration. Version numbers start at 1 and
+// increase by one for every migration.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *sql.Tx) error
+}
+
+// SQL returns an Up function that executes stmt
+func SQL(stmt string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(stmt)
+		return err
+	}
+}
+
+// Status describes the schema version of a database relative to a Migrator
+type Status struct {
+	Current int
+	Latest  int
+	Pending []Migration
+}
+
+// UpToDate reports whether there are no pending migrations
+func (s Status) UpToDate() bool {
+	return len(s.Pending) == 0
+}
+
+// Migrator applies migrations, tracking the schema version in PRAGMA user_version
+type Migrator struct {
+	migrations []Migration
+}
+
+// New creates a Migrator. It panics if versions are not numbered 1..n in order,
+// since that is a programming error rather than a runtime condition.
+func New(migrations ...Migration) *Migrator {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			panic(fmt.Sprintf("migrate: migration %d has version %d, expected %d", i, m.Version, i+1))
+		}
+		if m.Up == nil {
+			panic(fmt.Sprintf("migrate: migration %d has no Up function", m.Version))
+		}
+	}
+
+	return &Migrator{migrations: migrations}
+}
+
+// Latest returns the version the schema has after every migration is applied
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Status reports the current schema version of db and the migrations that
+// Up would apply, without changing anything
+func (m *Migrator) Status(db *sql.DB) (Status, error) {
+	current, err := Version(db)
+	if err != nil {
+		return Status{}, err
+	}
+
+	if current > m.Latest() {
+		return Status{}, errs.New(SchemaTooNewErrCode, "migrate: database schema is newer than this version supports").
+			WithContext("version", current).
+			WithContext("latest", m.Latest())
+	}
+
+	return Status{
+		Current: current,
+		Latest:  m.Latest(),
+		Pending: m.migrations[current:],
+	}, nil
+}
+
+// Up applies every pending migration in order. Each migration runs in its own
+// transaction together with the version bump, so a failure leaves the database
+// at the last successfully applied version. The applied migrations are returned.
+func (m *Migrator) Up(db *sql.DB) ([]Migration, error) {
+	status, err := m.Status(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, migration := range status.Pending {
+		if err := apply(db, migration); err != nil {
+			return applied, err
+		}
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// Version returns the schema version recorded in db
+func Version(db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, errs.Wrap(err, MigrationFailedErrCode, "migrate: failed to read schema version")
+	}
+	return version, nil
+}
+
+func apply(db *sql.DB, migration Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errs.Wrap(err, MigrationFailedErrCode, "migrate: failed to begin transaction").
+			WithContext("version", migration.Version)
+	}
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	if err := migration.Up(tx); err != nil {
+		return errs.Wrap(err, MigrationFailedErrCode, "migrate: migration failed").
+			WithContext("version", migration.Version).
+			WithContext("description", migration.Description)
+	}
+
+	// PRAGMA does not accept bound parameters; the version is an int we control
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", migration.Version)); err != nil {
+		return errs.Wrap(err, MigrationFailedErrCode, "migrate: failed to record schema version").
+			WithContext("version", migration.Version)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errs.Wrap(err, MigrationFailedErrCode, "migrate: failed to commit migration").
+			WithContext("version", migration.Version)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/tomdoesdev/knox/kit/errs"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count)
+	if err != nil {
+		t.Fatalf("failed to query sqlite_master: %v", err)
+	}
+	return count == 1
+}
+
+func TestMigrator_Up(t *testing.T) {
+	db := openTestDB(t)
+
+	m := New(
+		Migration{Version: 1, Description: "create a", Up: SQL("CREATE TABLE a (id INTEGER)")},
+		Migration{Version: 2, Description: "create b", Up: SQL("CREATE TABLE b (id INTEGER)")},
+	)
+
+	applied, err := m.Up(db)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if len(applied) != 2 {
+		t.Errorf("Up() applied %d migrations, want 2", len(applied))
+	}
+
+	version, err := Version(db)
+	if err != nil {
+		t.Fatalf("Version() error = %v", err)
+	}
+	if version != 2 {
+		t.Errorf("Version() = %d, want 2", version)
+	}
+
+	if !tableExists(t, db, "a") || !tableExists(t, db, "b") {
+		t.Error("expected tables a and b to exist")
+	}
+
+	applied, err = m.Up(db)
+	if err != nil {
+		t.Fatalf("second Up() error = %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("second Up() applied %d migrations, want 0", len(applied))
+	}
+}
+
+func TestMigrator_Status(t *testing.T) {
+	db := openTestDB(t)
+
+	first := New(Migration{Version: 1, Up: SQL("CREATE TABLE a (id INTEGER)")})
+	if _, err := first.Up(db); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	second := New(
+		Migration{Version: 1, Up: SQL("CREATE TABLE a (id INTEGER)")},
+		Migration{Version: 2, Up: SQL("CREATE TABLE b (id INTEGER)")},
+	)
+
+	status, err := second.Status(db)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Current != 1 || status.Latest != 2 {
+		t.Errorf("Status() = current %d latest %d, want 1 and 2", status.Current, status.Latest)
+	}
+	if len(status.Pending) != 1 || status.Pending[0].Version != 2 {
+		t.Errorf("Status() pending = %v, want only version 2", status.Pending)
+	}
+	if tableExists(t, db, "b") {
+		t.Error("Status() must not apply migrations")
+	}
+}
+
+func TestMigrator_UpRollsBackFailedMigration(t *testing.T) {
+	db := openTestDB(t)
+
+	m := New(
+		Migration{Version: 1, Up: SQL("CREATE TABLE a (id INTEGER)")},
+		Migration{Version: 2, Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec("CREATE TABLE b (id INTEGER)"); err != nil {
+				return err
+			}
+			return errors.New("boom")
+		}},
+	)
+
+	applied, err := m.Up(db)
+	if !errs.Is(err, MigrationFailedErrCode) {
+		t.Fatalf("Up() error = %v, want %s", err, MigrationFailedErrCode)
+	}
+	if len(applied) != 1 {
+		t.Errorf("Up() applied %d migrations, want 1", len(applied))
+	}
+
+	version, err := Version(db)
+	if err != nil {
+		t.Fatalf("Version() error = %v", err)
+	}
+	if version != 1 {
+		t.Errorf("Version() = %d, want 1", version)
+	}
+	if tableExists(t, db, "b") {
+		t.Error("failed migration should have been rolled back")
+	}
+}
+
+func TestMigrator_StatusRejectsNewerSchema(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := db.Exec("PRAGMA user_version = 5"); err != nil {
+		t.Fatalf("failed to set user_version: %v", err)
+	}
+
+	m := New(Migration{Version: 1, Up: SQL("CREATE TABLE a (id INTEGER)")})
+	if _, err := m.Up(db); !errs.Is(err, SchemaTooNewErrCode) {
+		t.Errorf("Up() error = %v, want %s", err, SchemaTooNewErrCode)
+	}
+}
+
+func TestNew_PanicsOnOutOfOrderVersions(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("New() should panic on out of order versions")
+		}
+	}()
+
+	New(
+		Migration{Version: 2, Up: SQL("SELECT 1")},
+		Migration{Version: 1, Up: SQL("SELECT 1")},
+	)
+}
@@ -6,13 +6,11 @@ import (
 	"strings"
 )
 
+// UserHomeDir returns the user's home directory, or "" when it is not set
 func UserHomeDir() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		home = os.Getenv("HOME")
-	}
-	if home == "" {
-		panic("$HOME not set")
+		return ""
 	}
 	return home
 }
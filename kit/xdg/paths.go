@@ -6,6 +6,43 @@ import (
 	"github.com/tomdoesdev/knox/kit/xdg/internal"
 )
 
-func initBaseDirs(home string) {
-	ConfigHome = internal.EnvPath(envConfigHome, filepath.Join(home, ".config"))
+// Base directories are resolved on every call rather than at init, so a
+// missing home directory only fails the callers that need one.
+
+// Home returns the user's home directory, or "" when it cannot be resolved
+func Home() string {
+	return internal.UserHomeDir()
+}
+
+// ConfigHome returns $XDG_CONFIG_HOME, falling back to ~/.config. It is ""
+// when neither can be resolved.
+func ConfigHome() string {
+	return internal.EnvPath(envConfigHome, homePath(".config"))
+}
+
+// DataHome returns $XDG_DATA_HOME, falling back to ~/.local/share. It is ""
+// when neither can be resolved.
+func DataHome() string {
+	return internal.EnvPath(envDataHome, homePath(".local", "share"))
+}
+
+// StateHome returns $XDG_STATE_HOME, falling back to ~/.local/state. It is ""
+// when neither can be resolved.
+func StateHome() string {
+	return internal.EnvPath(envStateHome, homePath(".local", "state"))
+}
+
+// ExpandHome replaces a leading ~ or $HOME in path with the user's home
+// directory. path is returned unchanged when the home directory is unknown.
+func ExpandHome(path string) string {
+	return internal.ExpandHome(path)
+}
+
+// homePath joins elem onto the home directory, or returns "" without one
+func homePath(elem ...string) string {
+	home := Home()
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(append([]string{home}, elem...)...)
 }
@@ -5,19 +5,13 @@ import (
 
 	"github.com/tomdoesdev/knox/kit/errs"
 	"github.com/tomdoesdev/knox/kit/fs"
-	"github.com/tomdoesdev/knox/kit/xdg/internal"
 )
 
 const (
 	envDataHome   = "XDG_DATA_HOME"
 	envDataDirs   = "XDG_DATA_DIRS"
 	envConfigHome = "XDG_CONFIG_HOME"
-)
-
-var (
-	Home       = internal.UserHomeDir()
-	ConfigHome string
-	DataHome   string
+	envStateHome  = "XDG_STATE_HOME"
 )
 
 var (
@@ -26,16 +20,14 @@ var (
 	TouchFailureErrCode   errs.Code = "TOUCH_FAILURE"
 )
 
-func init() {
-	initBaseDirs(Home)
-}
-
 func ConfigFile(appName, filename string) (string, error) {
-	p := filepath.Join(ConfigHome, appName)
-	if p == "" {
-		return "", errs.New(BadPathErrCode, "path for config file is empty").WithPath(p)
+	configHome := ConfigHome()
+	if configHome == "" {
+		return "", errs.New(BadPathErrCode, "xdg: config home is not set")
 	}
 
+	p := filepath.Join(configHome, appName)
+
 	err := fs.MkdirAll(p, 0600)
 	if err != nil {
 		return "", errs.Wrap(err, MakeDirFailureErrCode, "xdg: failed to make config dirs").WithPath(p)
@@ -54,3 +46,20 @@ func ConfigFile(appName, filename string) (string, error) {
 	return configPath, nil
 
 }
+
+// StateDir returns the state directory of appName, creating it with 0700
+// permissions if needed. State is data that should survive restarts but is
+// not important enough to back up, such as history and undo information.
+func StateDir(appName string) (string, error) {
+	stateHome := StateHome()
+	if stateHome == "" {
+		return "", errs.New(BadPathErrCode, "xdg: state home is not set")
+	}
+
+	p := filepath.Join(stateHome, appName)
+	if err := fs.MkdirAll(p, 0700); err != nil {
+		return "", errs.Wrap(err, MakeDirFailureErrCode, "xdg: failed to make state dirs").WithPath(p)
+	}
+
+	return p, nil
+}